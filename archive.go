@@ -0,0 +1,143 @@
+package httpu
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	utils "github.com/hazbo/httpu/utils/common"
+)
+
+// archiveExtensions are the extensions of the archives that projects can be
+// loaded from.
+var archiveExtensions = []string{".zip", ".tar", ".tar.gz", ".tgz"}
+
+// isArchive checks whether the given path has the extension of an archive.
+func isArchive(name string) bool {
+	for _, ext := range archiveExtensions {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// OpenArchive reads the zip or tar archive at the given path, which can also be
+// gzipped, returning a file system of the files within it. Problems with any of
+// the files are shown with the path of the archive.
+func OpenArchive(name string) (fs.FS, error) {
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var fsys fs.FS
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		fsys, err = zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	case strings.HasSuffix(name, ".tar"):
+		fsys, err = TarFS(bytes.NewReader(b))
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		var gz *gzip.Reader
+		if gz, err = gzip.NewReader(bytes.NewReader(b)); err == nil {
+			fsys, err = TarFS(gz)
+		}
+	default:
+		return nil, fmt.Errorf("\"%s\" is not a zip or tar archive", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Could not read archive \"%s\": %s", name, err)
+	}
+	return utils.NamedFS(fsys, name), nil
+}
+
+// TarFS reads every file within the tar archive into memory, returning a file
+// system of them. The files are stored within a zip archive, which is already a
+// file system, as tar archives can only be read from start to end.
+func TarFS(r io.Reader) (fs.FS, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		name := utils.CleanPath(hdr.Name)
+		if name == "." || strings.HasPrefix(name, "../") {
+			continue
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			_, err = zw.Create(name + "/")
+		case tar.TypeReg:
+			var w io.Writer
+			w, err = zw.CreateHeader(&zip.FileHeader{
+				Name:     name,
+				Method:   zip.Store,
+				Modified: hdr.ModTime,
+			})
+			if err == nil {
+				_, err = io.Copy(w, tr)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+}
+
+// splitArchive splits the path of a project within an archive into the path of
+// the archive and the directory of the project within it, which is the root of
+// the archive unless it is given after the path of the archive, such as
+// packages.zip/moltin. False is returned if no archive is found along the path.
+func splitArchive(p string) (string, string, bool) {
+	elems := strings.Split(filepath.ToSlash(p), "/")
+	for i := range elems {
+		archive := filepath.FromSlash(strings.Join(elems[:i+1], "/"))
+		if !isArchive(archive) {
+			continue
+		}
+		if info, err := os.Stat(archive); err == nil && info.Mode().IsRegular() {
+			dir := strings.Join(elems[i+1:], "/")
+			if dir == "" {
+				dir = "."
+			}
+			return archive, dir, true
+		}
+	}
+	return "", "", false
+}
+
+// projectFS returns the file system that the given project is loaded from,
+// along with the directory of the project within it. Projects are given
+// relative to the base directory, either as a directory or as the path of an
+// archive, which the project is loaded from in place of the base directory.
+func projectFS(base, project string) (fs.FS, string, error) {
+	p := project
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(base, p)
+	}
+	if archive, dir, ok := splitArchive(p); ok {
+		fsys, err := OpenArchive(archive)
+		return fsys, dir, err
+	}
+	return utils.DirFS(base), project, nil
+}
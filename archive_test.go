@@ -0,0 +1,141 @@
+package httpu
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// archiveFiles is a project within the api directory of an archive.
+var archiveFiles = map[string]string{
+	"api/project.yaml": "project:\n  url: http://localhost\n" +
+		"  resourceFiles:\n    - api/requests\n",
+	"api/requests/users.yaml": "kind: request\nname: users\nspec:\n" +
+		"  uri: /users\n  method: POST\n  data:\n    fromFile: api/data/user.json\n",
+	"api/data/user.json": `{"name": "hazbo"}`,
+	"api/README":         "The users API.",
+}
+
+func writeZip(t *testing.T, name string, files map[string]string) {
+	f, err := os.Create(name)
+	assert.Nil(t, err)
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	for n, contents := range files {
+		w, err := zw.Create(n)
+		assert.Nil(t, err)
+		io.WriteString(w, contents)
+	}
+	assert.Nil(t, zw.Close())
+}
+
+func writeTarGz(t *testing.T, name string, files map[string]string) {
+	f, err := os.Create(name)
+	assert.Nil(t, err)
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for n, contents := range files {
+		assert.Nil(t, tw.WriteHeader(&tar.Header{
+			Name: "./" + n, Mode: 0644, Size: int64(len(contents)),
+			Typeflag: tar.TypeReg,
+		}))
+		io.WriteString(tw, contents)
+	}
+	assert.Nil(t, tw.Close())
+	assert.Nil(t, gz.Close())
+}
+
+func TestConfigureFromArchive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "archive")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	archive := filepath.Join(dir, "api.zip")
+	writeZip(t, archive, archiveFiles)
+
+	assert.Nil(t, ConfigureFromFile(archive+"/api"))
+	assert.Equal(t, archive+"/api", Session().ProjectPath)
+	assert.Equal(t, []string{archive}, session.Watched())
+	r, ok := Session().Requests["users"]
+	assert.True(t, ok)
+	assert.Equal(t, `{"name": "hazbo"}`, r.Spec.Data.String())
+	readme, err := Session().README()
+	assert.Nil(t, err)
+	assert.Equal(t, "The users API.", string(readme))
+
+	// Problems are shown with the path of the archive.
+	writeZip(t, archive, map[string]string{
+		"project.json": `{"project": {"url": "http://localhost",
+			"resourceFiles": ["missing.json"]}}`,
+	})
+	ps, err := Validate(archive)
+	assert.Nil(t, err)
+	assert.Equal(t, archive+
+		`/project.json:2:22: resource file "missing.json" does not exist `+
+		`(looked for `+archive+`/missing.json)`, ps.Error())
+}
+
+func TestClientLoadArchive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "archive")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	archive := filepath.Join(dir, "api.tar.gz")
+	writeTarGz(t, archive, archiveFiles)
+
+	c := NewClient()
+	assert.Nil(t, c.Load(archive+"/api"))
+	r, _, err := c.Find("users")
+	assert.Nil(t, err)
+	assert.Equal(t, `{"name": "hazbo"}`, r.Spec.Data.String())
+
+	// The archive is opened again when the project is reloaded.
+	files := map[string]string{}
+	for n, contents := range archiveFiles {
+		files[n] = contents
+	}
+	files["api/data/user.json"] = `{"name": "updated"}`
+	writeTarGz(t, archive, files)
+	assert.Nil(t, c.Reload())
+	r, _, err = c.Find("users")
+	assert.Nil(t, err)
+	assert.Equal(t, `{"name": "updated"}`, r.Spec.Data.String())
+
+	err = c.Load(filepath.Join(dir, "none.zip"))
+	assert.True(t, strings.HasPrefix(err.Error(), "Error loading config: "))
+}
+
+func TestTarFS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "archive")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	archive := filepath.Join(dir, "api.tgz")
+	writeTarGz(t, archive, archiveFiles)
+	fsys, err := OpenArchive(archive)
+	assert.Nil(t, err)
+
+	b, err := fs.ReadFile(fsys, "api/data/user.json")
+	assert.Nil(t, err)
+	assert.Equal(t, `{"name": "hazbo"}`, string(b))
+
+	// Directories that were not stored within the archive can still be read.
+	es, err := fs.ReadDir(fsys, "api")
+	assert.Nil(t, err)
+	assert.Len(t, es, 4)
+
+	_, err = OpenArchive(filepath.Join(dir, "api.rar"))
+	assert.NotNil(t, err)
+}
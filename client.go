@@ -0,0 +1,212 @@
+package httpu
+
+import (
+	"fmt"
+	"io/fs"
+	"net/http"
+	"sync"
+
+	"github.com/hazbo/httpu/env"
+	"github.com/hazbo/httpu/prompt"
+	"github.com/hazbo/httpu/resource"
+	"github.com/hazbo/httpu/resource/request"
+	"github.com/hazbo/httpu/stash"
+	utils "github.com/hazbo/httpu/utils/common"
+)
+
+// Client loads a project and makes its requests. Everything a project is made
+// up of, from its requests to the values within its stash, is held by the
+// client rather than by any global state, so that any number of projects can
+// be used at once from within the same program. The command line and user
+// interface each load their project into a client of their own.
+//
+// The requests of a client can be made from multiple goroutines at once, and
+// values can be set within its stash, prompts and env while they are.
+type Client struct {
+	mu sync.RWMutex
+
+	// scope holds the file system that the project was loaded from, the
+	// defaults of the project, the stash, prompt and env values that requests
+	// are made with and the proxy that overrides any other. It is replaced
+	// each time a project is loaded, keeping the same values.
+	scope   *request.Scope
+	project Project
+
+	// open reads the project file of the project that was last loaded, along
+	// with the file system that it was found within.
+	open func() (fs.FS, string, []byte, error)
+
+	// watched holds the paths of the files and directories that the project
+	// was last loaded, or attempted to be loaded, from.
+	watched []string
+}
+
+// NewClient returns a client with nothing loaded, nothing stashed and no values
+// entered for any prompt. Environment variables are read from the environment
+// unless they are set using Env.
+func NewClient() *Client {
+	return &Client{scope: request.NewScope(nil)}
+}
+
+// Stash returns the stash that requests of the client are made with, which is
+// kept when a project is loaded.
+func (c *Client) Stash() *stash.Map {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.scope.Stash
+}
+
+// Prompts returns the values entered for prompts that requests of the client
+// are made with, which can be set before or after a project is loaded.
+func (c *Client) Prompts() *prompt.Map {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.scope.Prompts
+}
+
+// Env returns the values used for environment variables by requests of the
+// client, in place of those of the environment.
+func (c *Client) Env() *env.Map {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.scope.Env
+}
+
+// ProxyOverride returns the proxy that overrides any other for requests of the
+// client. The runtime proxy of the command line is never used.
+func (c *Client) ProxyOverride() *request.ProxyOverride {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.scope.ProxyOverride
+}
+
+// Load loads the project within the given directory on disk, in the same way as
+// LoadFS. As with the run command, the project is looked for within the working
+// directory and then within the home packages directory, with every path within
+// the project being relative to the directory it is found in. The directory can
+// also be a zip or tar archive, or a directory within one, such as
+// packages.zip/moltin, in which case every path is relative to the root of the
+// archive.
+func (c *Client) Load(dir string) error {
+	return c.load(func() (fs.FS, string, []byte, error) {
+		return findProject(dir)
+	})
+}
+
+// LoadFS loads the project within the given directory of the file system. The
+// project is validated before it is loaded, with every problem that is found
+// being returned as the error, in the same way as ConfigureFromFile. Every path
+// within the project, such as those of resource files, is relative to the root
+// of the file system.
+//
+// The project that was loaded before is kept when a project fails to load. The
+// values within the stash and those entered for prompts are kept either way.
+func (c *Client) LoadFS(fsys fs.FS, dir string) error {
+	return c.load(func() (fs.FS, string, []byte, error) {
+		name, cfg, err := readProjectFile(fsys, dir)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("Error loading config: %s", err)
+		}
+		return fsys, name, cfg, nil
+	})
+}
+
+// load loads the project whose project file is read by open, keeping open so
+// that the project can be read in the same way when it is reloaded. The files
+// that were read are watched even when the project fails to load, so that it
+// can be reloaded once they have been fixed.
+func (c *Client) load(open func() (fs.FS, string, []byte, error)) error {
+	fsys, name, cfg, err := open()
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s := *c.scope
+	s.FS = fsys
+	rs := resource.NewResources(&s)
+	p, files, err := configure(fsys, name, cfg, rs)
+	if err != nil {
+		c.watched = watchable(fsys, files)
+		return err
+	}
+	s.Defaults = p.defaults()
+
+	c.scope = &s
+	c.project = p
+	c.open = open
+	c.watched = watchable(fsys, append(files, append(
+		dataFiles(fsys, rs.Requests), utils.DisplayPath(fsys, p.readme()))...))
+	return nil
+}
+
+// Reload loads the project that was last loaded again, such as once any of its
+// files have changed. A project loaded from disk is looked for again, so that
+// an archive is opened again too.
+func (c *Client) Reload() error {
+	c.mu.RLock()
+	open := c.open
+	c.mu.RUnlock()
+
+	if open == nil {
+		return fmt.Errorf("No project has been loaded.")
+	}
+	return c.load(open)
+}
+
+// Project returns the project that was last loaded, along with its requests.
+func (c *Client) Project() Project {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.project
+}
+
+// Proxy returns the proxy that requests of the client are made through, unless
+// a request sets its own. This is the proxy of the project, or the one set by
+// ProxyOverride in its place. It is nil if requests are made through any proxy
+// set by the environment.
+func (c *Client) Proxy() *request.Proxy {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return request.Request{Scope: c.scope}.Proxy()
+}
+
+// Find looks up a request of the project by the name used within the command
+// bar, being either {request} or {request}.{variant}, in the same way as
+// resource.Find.
+func (c *Client) Find(name string) (request.Request, *request.Variant, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return resource.RequestMap(c.project.Requests).Find(name)
+}
+
+// Do makes the request with the given name, being either {request} or
+// {request}.{variant}, against the url of the project. Any arguments are
+// applied to the request in the same way as they are by the run command, such
+// as id=10, -H X-Debug:1 and ?page=2.
+//
+// The response body is left open for the caller to read and close. Prompts are
+// not asked for, so any values needed should be set using Prompts first.
+func (c *Client) Do(name string, args ...string) (
+	*http.Response, request.RequestStat, error) {
+	req, v, err := c.Find(name)
+	if err != nil {
+		return nil, request.RequestStat{}, err
+	}
+
+	a, err := request.ParseArgs(args)
+	if err != nil {
+		return nil, request.RequestStat{}, err
+	}
+	if err := a.Apply(&req, v); err != nil {
+		return nil, request.RequestStat{}, err
+	}
+
+	u := c.Project().URL
+	if v != nil {
+		return req.MakeWithVariant(u, v)
+	}
+	return req.Make(u)
+}
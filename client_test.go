@@ -0,0 +1,129 @@
+package httpu
+
+import (
+	"embed"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hazbo/httpu/resource"
+	"github.com/hazbo/httpu/stash"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientLoad(t *testing.T) {
+	resource.Requests = resource.RequestMap{}
+
+	c := NewClient()
+	assert.Nil(t, c.Load("./projects/modules_project"))
+	assert.Equal(t, "projects/modules_project", c.Project().ProjectPath)
+	assert.Contains(t, c.Project().Requests, "users")
+	assert.Empty(t, resource.Requests, "global requests should not be changed")
+
+	_, v, err := c.Find("users.as-a")
+	assert.Nil(t, err)
+	assert.Equal(t, "GET", v.Method)
+
+	// The project that was loaded before is kept when a project fails to load.
+	assert.NotNil(t, c.Load("./projects/invalid_project"))
+	assert.Contains(t, c.Project().Requests, "users")
+	assert.Nil(t, c.Reload())
+}
+
+//go:embed projects/modules_project
+var modulesProject embed.FS
+
+func TestClientLoadEmbed(t *testing.T) {
+	c := NewClient()
+	assert.Nil(t, c.LoadFS(modulesProject, "projects/modules_project"))
+	assert.Equal(t, resource.FilePaths{
+		"projects/modules_project/requests/a.json",
+		"projects/modules_project/requests/b.json",
+		"projects/modules_project/extra/deep/api.yaml",
+		"projects/modules_project/extra/deep/c.yaml",
+		"projects/modules_project/users/users.json",
+	}, c.Project().ResourceFiles)
+	assert.Contains(t, c.Project().Requests, "users")
+
+	_, err := c.Project().README()
+	assert.NotNil(t, err, "the project has no README")
+}
+
+func TestClientDo(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"path": "%s", "token": "%s"}`,
+				r.URL.Path, r.Header.Get("Authorization"))
+		}))
+	defer ts.Close()
+
+	project := func(token string) fstest.MapFS {
+		return fstest.MapFS{
+			"api/project.yaml": {Data: []byte(fmt.Sprintf(
+				"project:\n  url: %s\n  resourceFiles:\n    - api/requests.yaml\n",
+				ts.URL))},
+			"api/requests.yaml": {Data: []byte(fmt.Sprintf(`kind: request
+name: users
+spec:
+  uri: /users/{id}
+  method: GET
+  headers:
+    - header: Authorization
+      value: "%s"
+  stashValues:
+    - name: path
+      jsonPath: [path]
+`, token))},
+		}
+	}
+
+	a, b := NewClient(), NewClient()
+	assert.Nil(t, a.LoadFS(project("${prompt[token]}"), "api"))
+	assert.Nil(t, b.LoadFS(project("b"), "api"))
+	a.Prompts().Set("token", "a")
+
+	resp, _, err := a.Do("users", "id=1")
+	assert.Nil(t, err)
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.JSONEq(t, `{"path": "/users/1", "token": "a"}`, string(body))
+
+	resp, _, err = b.Do("users", "id=2")
+	assert.Nil(t, err)
+	resp.Body.Close()
+
+	// Each client has its own stash, neither of which is the global one.
+	sv, err := a.Stash().Get("path")
+	assert.Nil(t, err)
+	assert.Equal(t, "/users/1", sv.Value)
+	sv, err = b.Stash().Get("path")
+	assert.Nil(t, err)
+	assert.Equal(t, "/users/2", sv.Value)
+	_, err = stash.Get("path")
+	assert.NotNil(t, err)
+
+	// Values can be set while requests are being made.
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			a.Prompts().Set("token", fmt.Sprint(i))
+			a.Env().Set("TOKEN", fmt.Sprint(i))
+			if resp, _, err := a.Do("users", "id=1"); err == nil {
+				resp.Body.Close()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	_, _, err = a.Do("orders")
+	assert.EqualError(t, err, "Request \"orders\" does not exist.")
+	_, _, err = NewClient().Do("users")
+	assert.NotNil(t, err)
+}
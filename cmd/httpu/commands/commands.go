@@ -18,7 +18,9 @@ type CommandMap map[string]*Command
 
 // Commands is the list of commands within a map
 var Commands = CommandMap{
-	"new":     newCmd,
-	"pull":    pullCmd,
-	"version": versionCmd,
+	"new":      newCmd,
+	"pull":     pullCmd,
+	"run":      runCmd,
+	"validate": validateCmd,
+	"version":  versionCmd,
 }
@@ -0,0 +1,13 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package commands
+
+import (
+	"fmt"
+	"os"
+)
+
+// noEcho is not supported on this platform, so what is typed is always echoed.
+func noEcho(f *os.File) (func(), error) {
+	return nil, fmt.Errorf("Turning off echo is not supported.")
+}
@@ -0,0 +1,35 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package commands
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// noEcho turns off echoing of what is typed into the terminal of the given
+// file, returning the func that turns it back on. An error is returned if the
+// file is not a terminal.
+func noEcho(f *os.File) (func(), error) {
+	var t syscall.Termios
+	if err := ioctl(f.Fd(), ioctlGetTermios, &t); err != nil {
+		return nil, err
+	}
+	restore := t
+	t.Lflag &^= syscall.ECHO
+	if err := ioctl(f.Fd(), ioctlSetTermios, &t); err != nil {
+		return nil, err
+	}
+	return func() { ioctl(f.Fd(), ioctlSetTermios, &restore) }, nil
+}
+
+// ioctl gets or sets the termios of the terminal of the given file descriptor.
+func ioctl(fd, req uintptr, t *syscall.Termios) error {
+	_, _, e := syscall.Syscall(
+		syscall.SYS_IOCTL, fd, req, uintptr(unsafe.Pointer(t)))
+	if e != 0 {
+		return e
+	}
+	return nil
+}
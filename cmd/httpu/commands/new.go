@@ -15,37 +15,37 @@ var newFlagSet = flag.NewFlagSet("new", flag.ExitOnError)
 var (
 	newEnvFile = newFlagSet.String(
 		"e", "", "Loads .env file to start httpu with environment variables")
+	newNoReload = newFlagSet.Bool(
+		"no-reload", false, "Stops the project being reloaded as its files change")
 )
 
 func newValue(args []string) error {
 	newFlagSet.Parse(args)
 
 	// Handle 0 argument calls
-	if len(args) == 0 {
+	if newFlagSet.NArg() == 0 {
 		fmt.Printf("Error: Expecting 1 argument, 0 passed\n")
 		os.Exit(1)
 	}
 
-	// Get the first argument for the `new` command
-	p := args[0]
+	// Get the first argument after the options for the `new` command
+	p := newFlagSet.Arg(0)
 
 	if *newEnvFile != "" {
 		err := godotenv.Load(*newEnvFile)
 		if err != nil {
 			return fmt.Errorf("Could not find .env file: %s", *newEnvFile)
 		}
-
-		// If an env file has been passed, this becomes argument 2, over 0.
-		p = args[2]
 	}
 
-	err := httpu.ConfigureFromFile(p)
-	if err != nil {
+	c := httpu.NewClient()
+	if err := c.Load(p); err != nil {
 		return err
 	}
 
 	// Start the terminal user interface!
-	ui.New().Start()
+	ui.HotReload = !*newNoReload
+	ui.New(c).Start()
 
 	return nil
 }
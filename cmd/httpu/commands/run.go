@@ -0,0 +1,247 @@
+package commands
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/gorilla/websocket"
+	"github.com/hazbo/httpu"
+	"github.com/hazbo/httpu/prompt"
+	"github.com/hazbo/httpu/resource/request"
+	"github.com/joho/godotenv"
+)
+
+var runFlagSet = flag.NewFlagSet("run", flag.ExitOnError)
+
+// promptValues holds the values for prompt variables passed through as flags,
+// in the format name=value.
+type promptValues map[string]string
+
+// String returns each prompt value in the format it was passed through in.
+func (pv promptValues) String() string {
+	var s []string
+	for n, v := range pv {
+		s = append(s, fmt.Sprintf("%s=%s", n, v))
+	}
+	return strings.Join(s, ",")
+}
+
+// Set is an implementation of flag.Value and adds a single prompt value.
+func (pv promptValues) Set(s string) error {
+	parts := strings.SplitN(s, "=", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("prompt values must be in the format name=value")
+	}
+	pv[parts[0]] = parts[1]
+	return nil
+}
+
+var (
+	runEnvFile = runFlagSet.String(
+		"e", "", "Loads .env file to run the request with environment variables")
+	runPrompts = promptValues{}
+	runProxy   = runFlagSet.String(
+		"x", "", "Makes the request through the given proxy, or directly if set to off")
+)
+
+func init() {
+	runFlagSet.Var(runPrompts, "p",
+		"Sets the value of a prompt variable as name=value, can be repeated")
+}
+
+func runValue(args []string) error {
+	runFlagSet.Parse(args)
+
+	if runFlagSet.NArg() < 2 {
+		return fmt.Errorf(
+			"Error: Expecting 2 arguments, %d passed", runFlagSet.NArg())
+	}
+
+	p, name := runFlagSet.Arg(0), runFlagSet.Arg(1)
+
+	if *runEnvFile != "" {
+		err := godotenv.Load(*runEnvFile)
+		if err != nil {
+			return fmt.Errorf("Could not find .env file: %s", *runEnvFile)
+		}
+	}
+
+	c := httpu.NewClient()
+	if err := c.Load(p); err != nil {
+		return err
+	}
+
+	switch *runProxy {
+	case "":
+	case "off":
+		c.ProxyOverride().SetOff(true)
+	default:
+		if _, err := request.ParseProxyURL(*runProxy); err != nil {
+			return err
+		}
+		c.ProxyOverride().Set(*runProxy)
+	}
+
+	req, v, err := c.Find(name)
+	if err != nil {
+		return err
+	}
+
+	a, err := request.ParseArgs(runFlagSet.Args()[2:])
+	if err != nil {
+		return err
+	}
+	if err := a.Apply(&req, v); err != nil {
+		return err
+	}
+
+	// Prompt values that were not passed through as flags are read from stdin.
+	in := bufio.NewReader(os.Stdin)
+	prompts := c.Prompts()
+	for _, pr := range req.Prompts(v) {
+		value, ok := runPrompts[pr.Name]
+		if !ok {
+			value = readPrompt(in, pr, prompts)
+		}
+		prompts.Set(pr.Name, value)
+	}
+
+	if req.Kind == request.KindWebSocket {
+		return runWebSocket(&req, v, c.Project().URL, in)
+	}
+
+	var (
+		resp *http.Response
+		stat request.RequestStat
+	)
+	u := c.Project().URL
+	if v != nil {
+		resp, stat, err = req.MakeWithVariant(u, v)
+	} else {
+		resp, stat, err = req.Make(u)
+	}
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// The canonical string is written when a signed request is rejected, so
+	// that it can be compared with what the server expected.
+	if stat.Signature != "" && (resp.StatusCode == http.StatusUnauthorized ||
+		resp.StatusCode == http.StatusForbidden) {
+		fmt.Fprintf(os.Stderr,
+			"Signature rejected, canonical string:\n%s\n\n", stat.Signature)
+	}
+
+	if _, err := io.Copy(os.Stdout, resp.Body); err != nil {
+		return err
+	}
+
+	// Assertions that failed are written to stderr, so that the body can still
+	// be piped elsewhere while the command fails.
+	failed := request.Failed(stat.Assertions)
+	for _, ar := range failed {
+		fmt.Fprintf(os.Stderr, "✗ %s: %s\n", ar.Assertion, ar.Err)
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d assertions failed",
+			len(failed), len(stat.Assertions))
+	}
+	return nil
+}
+
+// runWebSocket opens a connection for a websocket resource, sending the message
+// of the variant if there is one, followed by each line read from stdin. Each
+// frame received is written to stdout on its own line until the connection is
+// closed by the server. The request is made against the given url of its
+// project.
+func runWebSocket(req *request.Request, v *request.Variant, u url.URL,
+	in *bufio.Reader) error {
+	done := make(chan error, 1)
+	ws, err := req.Connect(u, func(f request.Frame) {
+		switch {
+		case f.Err != nil:
+			done <- f.Err
+		case f.Incoming:
+			fmt.Fprintf(os.Stdout, "%s\n", f.Data)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	defer ws.Close()
+
+	if v != nil {
+		msg, err := req.Message(v)
+		if err != nil {
+			return err
+		}
+		if err := ws.Send(msg); err != nil {
+			return err
+		}
+	}
+
+	go func() {
+		for {
+			l, err := in.ReadString('\n')
+			if l = strings.TrimRight(l, "\r\n"); l != "" {
+				if err := ws.Send([]byte(l)); err != nil {
+					return
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	err = <-done
+	if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+		return nil
+	}
+	return err
+}
+
+// readPrompt asks for the value of a prompt on stderr and reads a single line
+// from stdin. If nothing is entered, the last value held for it within values
+// or its default value is used. What is typed for a secret prompt is not
+// echoed when stdin is a terminal.
+func readPrompt(r *bufio.Reader, p prompt.Prompt, values *prompt.Map) string {
+	if p.Secret || p.Initial(values) == "" {
+		fmt.Fprintf(os.Stderr, "%s: ", p.Label())
+	} else {
+		fmt.Fprintf(os.Stderr, "%s [%s]: ", p.Label(), p.Initial(values))
+	}
+
+	if p.Secret {
+		if restore, err := noEcho(os.Stdin); err == nil {
+			defer fmt.Fprintln(os.Stderr)
+			defer restore()
+		}
+	}
+
+	l, _ := r.ReadString('\n')
+	l = strings.TrimRight(l, "\r\n")
+	if l == "" {
+		return p.Initial(values)
+	}
+	return l
+}
+
+var runCmd = &Command{
+	Usage: func(arg0 string) {
+		fmt.Printf(
+			"Usage: %s run [<options>...] <package_name> <request>[.<variant>] [<argument>...]\n\nOptions:\n",
+			arg0)
+		runFlagSet.PrintDefaults()
+	},
+	RunMethod: func(args []string) error {
+		return runValue(args)
+	},
+}
@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/hazbo/httpu"
+)
+
+var validateFlagSet = flag.NewFlagSet("validate", flag.ExitOnError)
+
+func validateValue(args []string) error {
+	validateFlagSet.Parse(args)
+
+	if validateFlagSet.NArg() < 1 {
+		return fmt.Errorf("Error: Expecting 1 argument, 0 passed")
+	}
+	p := validateFlagSet.Arg(0)
+
+	ps, err := httpu.Validate(p)
+	if err != nil {
+		return err
+	}
+
+	// Each problem is written on its own line, so that editors can jump to
+	// the file, line and column that it is found at.
+	for _, pr := range ps {
+		fmt.Println(pr)
+	}
+	switch len(ps) {
+	case 0:
+		fmt.Printf("%s is valid\n", p)
+		return nil
+	case 1:
+		return fmt.Errorf("Found 1 problem in %s", p)
+	}
+	return fmt.Errorf("Found %d problems in %s", len(ps), p)
+}
+
+var validateCmd = &Command{
+	Usage: func(arg0 string) {
+		fmt.Printf("Usage: %s validate [<options>...] <package_name>\n\nOptions:\n", arg0)
+		validateFlagSet.PrintDefaults()
+		if validateFlagSet.NFlag() < 1 {
+			fmt.Println("\tNo options for this command")
+		}
+	},
+	RunMethod: func(args []string) error {
+		return validateValue(args)
+	},
+}
@@ -2,12 +2,18 @@ package httpu
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io/ioutil"
+	"io/fs"
 	"net/url"
+	"path"
 
 	"github.com/hazbo/httpu/resource"
+	"github.com/hazbo/httpu/resource/format"
 	"github.com/hazbo/httpu/resource/request"
+	"github.com/hazbo/httpu/resource/request/auth"
+	"github.com/hazbo/httpu/resource/request/signer"
+	"github.com/hazbo/httpu/schema"
 	utils "github.com/hazbo/httpu/utils/common"
 )
 
@@ -19,77 +25,205 @@ type Config struct {
 // the base URL which is then extended in "request" Resources using a URI and a
 // path within a Variant.
 //
+// Auth that is set here will be used for all requests, unless a request or
+// variant sets its own. The same goes for Sign, which signs each request,
+// Protocol, which sets the version of HTTP used to make each request, and
+// Transport, which sets how connections are made for each request. Proxy sets
+// the proxy that requests are made through, in place of any proxy set by the
+// environment.
+//
 // Headers that are set here will apply to all requests, but can be overridden
 // within an individual request / variant.
 //
 // The resources are read as []string from the JSON, however as they are being
 // unmarsheled, the JSON for the filename is fetched and is unmarsheled into the
-// Resources that exist within Base.
+// Resources that exist within Base. Each of these can also be a directory or a
+// glob pattern. Include lists other projects whose resource files are loaded
+// after those of this project, so that large APIs can be split into modules.
 type Project struct {
 	URL           url.URL            `json:"url"`
 	ResourceFiles resource.FilePaths `json:"resourceFiles"`
+	Include       []string           `json:"include"`
+	Auth          *auth.Auth         `json:"auth"`
+	Sign          *signer.Config     `json:"sign"`
+	Protocol      string             `json:"protocol"`
+	Transport     *request.Transport `json:"transport"`
+	Proxy         *request.Proxy     `json:"proxy"`
 	ProjectPath   string
 
 	Requests map[string]request.Request
+
+	// fsys is the file system that the project was loaded from, with dir being
+	// the directory of the project within it.
+	fsys fs.FS
+	dir  string
 }
 
 const (
 	packagesDir     = ".httpu/packages"
-	projectFileName = "project.json"
+	projectFileName = "project"
 )
 
-// ConfigureFromFile reads in a base JSON config file and decodes it into Config
+// ConfigureFromFile reads in a base config file and decodes it into Config. The
+// project file can be written as JSON, which can contain comments, or as YAML
+// or TOML. The project is validated before it is loaded, with every problem
+// found within the project and resource files being returned as the error.
+//
+// The project is loaded into a client held by the package, in the same way as
+// Client.Load, so that the project loaded before is kept when a project fails
+// to load. Anything that needs more than Session should use a Client instead.
 func ConfigureFromFile(filePath string) error {
-	var (
-		cfg []byte
-		err error
-		c   Config
-	)
+	return session.Load(filePath)
+}
 
-	// Set a default base project path
-	utils.ProjectPath = "./"
+// configure validates the project file found at the given path within the file
+// system, then loads each of the resource files of the project into the given
+// resources, which are expected to be empty. The project is returned with its
+// requests, along with the paths of the files that were read, which are
+// returned even when the project fails to load.
+func configure(fsys fs.FS, name string, cfg []byte,
+	rs *resource.Resources) (Project, []string, error) {
+	c, files, ps := validate(fsys, name, cfg)
+	if len(ps) > 0 {
+		return Project{}, files, ps
+	}
 
-	if cfg, err = ioutil.ReadFile(
-		fmt.Sprintf("./%s/%s", filePath, projectFileName)); err != nil {
+	for _, rf := range c.Project.ResourceFiles {
+		if err := rs.Load(rf); err != nil {
+			return Project{}, files, err
+		}
+	}
+	if err := rs.Resolve(); err != nil {
+		return Project{}, files, err
+	}
 
-		// Look for project in the home packages directory
-		hd, _ := utils.HomeDir()
-		cfg, err = ioutil.ReadFile(fmt.Sprintf(
-			"%s/%s/%s/%s", hd, packagesDir, filePath, projectFileName))
+	c.Project.Requests = rs.Requests
+	c.Project.ProjectPath = utils.DisplayPath(fsys, path.Dir(name))
+	c.Project.fsys, c.Project.dir = fsys, path.Dir(name)
+	return c.Project, files, nil
+}
 
-		if err != nil {
-			return fmt.Errorf("Error loading config: %s", err)
-		}
+// README returns the contents of the README of the project, which is read from
+// the file system that the project was loaded from.
+func (p Project) README() ([]byte, error) {
+	if p.fsys == nil {
+		return nil, fs.ErrNotExist
+	}
+	return fs.ReadFile(p.fsys, p.readme())
+}
+
+// readme returns the path of the README of the project within its file system.
+func (p Project) readme() string {
+	return path.Join(p.dir, "README")
+}
 
-		// Override the project path to the home directory path
-		utils.ProjectPath = fmt.Sprintf("%s/%s", hd, packagesDir)
+// defaults returns the defaults that each request of the project is made with,
+// unless a request or variant sets its own.
+func (p Project) defaults() request.Defaults {
+	return request.Defaults{
+		Auth:      p.Auth,
+		Sign:      p.Sign,
+		Protocol:  p.Protocol,
+		Transport: p.Transport,
+		Proxy:     p.Proxy,
 	}
+}
 
-	err = json.Unmarshal(cfg, &c)
+// Validate checks the project file of the given project against the project
+// schema, along with each of its resource files, returning every problem that
+// is found. An error is only returned if the project cannot be found.
+func Validate(filePath string) (schema.Problems, error) {
+	fsys, name, cfg, err := findProject(filePath)
 	if err != nil {
-		return fmt.Errorf("Unable to parse JSON: %s", err)
+		return nil, err
 	}
+	_, _, ps := validate(fsys, name, cfg)
+	return ps, nil
+}
 
-	c.Project.ProjectPath = fmt.Sprintf("%s/%s", utils.ProjectPath, filePath)
+// validate validates the contents of the project file found at the given path
+// within the file system, followed by each of the resource files that it and
+// the projects it includes load, returning the decoded config along with any
+// problems found. The resource files of the config are replaced by every file
+// that is loaded, in the order that they are loaded. The paths of the files
+// that were read are returned too, even when there are problems.
+func validate(fsys fs.FS, name string,
+	cfg []byte) (Config, []string, schema.Problems) {
+	pf := projectFiles{
+		fsys:     fsys,
+		seen:     map[resource.FilePath]bool{},
+		included: map[string]bool{},
+	}
+	c, ok := pf.add(name, cfg)
+	if !ok {
+		return c, pf.watched(), pf.problems
+	}
+	c.Project.ResourceFiles = pf.files
+	return c, pf.watched(), append(pf.problems, pf.files.Validate(fsys)...)
+}
+
+// findProject finds the project file of the given project, looking within the
+// current directory and then within the home packages directory. The project
+// can also be an archive, or a directory within one, such as packages.zip/moltin.
+// The file system of the directory or archive that it is found within is
+// returned along with the path of the project file within it.
+func findProject(filePath string) (fs.FS, string, []byte, error) {
+	fsys, name, cfg, err := openProject("./", filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+
+		// Look for project in the home packages directory
+		hd, _ := utils.HomeDir()
+		dir := fmt.Sprintf("%s/%s", hd, packagesDir)
+		fsys, name, cfg, err = openProject(dir, filePath)
 
-	for _, rf := range c.Project.ResourceFiles {
-		err := rf.Load()
 		if err != nil {
-			return err
+			return nil, "", nil, fmt.Errorf("Error loading config: %s", err)
 		}
+	} else if err != nil {
+		return nil, "", nil, fmt.Errorf("Error loading config: %s", err)
 	}
+	return fsys, name, cfg, nil
+}
 
-	c.Project.Requests = resource.Requests
-
-	session = c.Project
+// openProject opens the file system of the given project within the base
+// directory, then reads its project file.
+func openProject(base, filePath string) (fs.FS, string, []byte, error) {
+	fsys, dir, err := projectFS(base, filePath)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	name, cfg, err := readProjectFile(fsys, dir)
+	return fsys, name, cfg, err
+}
 
-	return nil
+// readProjectFile reads the project file within the given directory of the file
+// system, returning its path along with its contents. The file is looked for
+// with each supported extension in turn, starting with project.json, which is
+// the file named in the error returned if none of them exist.
+func readProjectFile(fsys fs.FS, dir string) (string, []byte, error) {
+	var first error
+	for _, ext := range format.Extensions {
+		p := path.Join(utils.CleanPath(dir), projectFileName+ext)
+		b, err := fs.ReadFile(fsys, p)
+		if err == nil {
+			return p, b, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", nil, err
+		}
+		if first == nil {
+			first = err
+		}
+	}
+	return "", nil, first
 }
 
-var session Project
+// session is the client that projects are loaded into by ConfigureFromFile.
+var session = NewClient()
 
+// Session returns the project that was last loaded by ConfigureFromFile.
 func Session() Project {
-	return session
+	return session.Project()
 }
 
 // UnmarshalJSON is an implemenation of json.Unmarshaler and is used to parse
@@ -1,8 +1,10 @@
 package httpu
 
 import (
+	"strings"
 	"testing"
 
+	"github.com/hazbo/httpu/resource"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -10,3 +12,56 @@ func TestConfigureFromFile(t *testing.T) {
 	err := ConfigureFromFile("./projects/test_project")
 	assert.Equal(t, nil, err, "it should be nil")
 }
+
+func TestValidate(t *testing.T) {
+	ps, err := Validate("./projects/test_project")
+	assert.Nil(t, err)
+	assert.Empty(t, ps)
+
+	ps, err = Validate("./projects/invalid_project")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		`projects/invalid_project/project.yaml:3:3: unknown field "protocl" (did you mean "protocol"?)`,
+		`projects/invalid_project/project.yaml:7:7: resource file "projects/invalid_project/requests/missing.json" does not exist`,
+		`projects/invalid_project/project.yaml:8:7: no resource files match "projects/invalid_project/requests/**/*.jsn"`,
+		`projects/invalid_project/project.yaml:11:7: included project "projects/invalid_project/modules" does not exist`,
+		`projects/invalid_project/requests/users.json:6:5: unknown field "mehtod" (did you mean "method"?)`,
+		`projects/invalid_project/requests/users.json:8:26: invalid method "PSOT" (did you mean "POST"?)`,
+		`projects/invalid_project/requests/users.json:9:8: duplicate variant name "create" (first used at projects/invalid_project/requests/users.json:8:8)`,
+		`projects/invalid_project/requests/users.json:10:17: data file "projects/invalid_project/data/user.json" does not exist`,
+		`projects/invalid_project/requests/users.json:11:25: unknown variant or request "craete" (did you mean "create"?)`,
+		`projects/invalid_project/requests/orders.toml:1:1: invalid kind "reqest" (did you mean "request"?)`,
+		`projects/invalid_project/requests/orders.toml:2:1: duplicate request name "users" (first used at projects/invalid_project/requests/users.json:3:3)`,
+		`projects/invalid_project/requests/search.yaml:4:3: unknown template "jsonapi"`,
+		`projects/invalid_project/requests/search.yaml:7:3: invalid timeout "soon" (expected a duration such as "10s")`,
+		"projects/invalid_project/requests/search.yaml:10:7: invalid matches \"[a-\", error parsing regexp: missing closing ]: `[a-`",
+	}, strings.Split(ps.Error(), "\n"))
+
+	err = ConfigureFromFile("./projects/invalid_project")
+	assert.Equal(t, ps, err)
+}
+
+func TestConfigureIncludes(t *testing.T) {
+	assert.Nil(t, ConfigureFromFile("./projects/modules_project"))
+	assert.Equal(t, resource.FilePaths{
+		"projects/modules_project/requests/a.json",
+		"projects/modules_project/requests/b.json",
+		"projects/modules_project/extra/deep/api.yaml",
+		"projects/modules_project/extra/deep/c.yaml",
+		"projects/modules_project/users/users.json",
+	}, Session().ResourceFiles)
+	assert.Contains(t, Session().Requests, "users")
+
+	// Variants can extend requests from other files.
+	v, err := Session().Requests["users"].Variant("as-a")
+	assert.Nil(t, err)
+	assert.Equal(t, "GET", v.Method)
+
+	// Templates are not requests, but their defaults are used by those that
+	// name them.
+	assert.NotContains(t, Session().Requests, "jsonApi")
+	c := Session().Requests["c"]
+	assert.Equal(t, "application/json", c.Spec.Headers.Get("Accept"))
+	assert.Equal(t, "10s", c.Spec.Timeout)
+	assert.Len(t, c.Spec.Assertions, 1)
+}
@@ -2,12 +2,22 @@ package env
 
 import (
 	"os"
+	"sync"
 
 	"github.com/hazbo/httpu/utils/varparser"
 )
 
-// store is a map of stash values referenced by name.
-type store string
+// Map is a map of values used for environment variables, referenced by name.
+// Variables that are not within the map are taken from the environment, so
+// that projects being used from Go can set their own without changing the
+// environment of the whole process.
+//
+// Values can be set while requests are being made, so each map is guarded by a
+// lock of its own. The zero value is an empty map ready to use.
+type Map struct {
+	mu     sync.RWMutex
+	values map[string]string
+}
 
 func List() []string {
 	envList := make([]string, 0)
@@ -22,17 +32,40 @@ func Set(name, value string) error {
 	return os.Setenv(name, value)
 }
 
-// Replace is used to replace a variable with a value stored inside of the
-// stash.
-func (s store) Replace(k string) string {
+// Set sets the value used for the variable with the given name, in place of
+// the environment variable.
+func (m *Map) Set(name, value string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.values == nil {
+		m.values = map[string]string{}
+	}
+	m.values[name] = value
+}
+
+// Replace is used to replace a variable with the value held for it within the
+// map, or with the environment variable of the same name.
+func (m *Map) Replace(k string) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if v, ok := m.values[k]; ok {
+		return v
+	}
 	return os.Getenv(k)
 }
 
-// Store is an instance of the stash store.
-var Store store
+// Parse replaces each environment variable within the given string in the
+// same way as Replace.
+func (m *Map) Parse(s string) string {
+	return varparser.New("env").Parse(s, m)
+}
+
+// Store is an instance of the env store, which is empty, so that every
+// variable is taken from the environment.
+var Store = &Map{}
 
 // Parse uses the built in varparser to find an instance of a variable, and in
-// this case replace it with a value that exists with in the stash store.
+// this case replace it with the environment variable of the same name.
 func Parse(s string) string {
-	return varparser.New("env").Parse(s, Store)
+	return Store.Parse(s)
 }
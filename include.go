@@ -0,0 +1,153 @@
+package httpu
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strconv"
+
+	"github.com/hazbo/httpu/resource"
+	"github.com/hazbo/httpu/schema"
+	utils "github.com/hazbo/httpu/utils/common"
+)
+
+// projectFiles gathers the resource files loaded by a project, followed by
+// those loaded by each of the projects it includes, in turn. Files are only
+// loaded once, however many paths match them, as are projects, however many
+// times they are included.
+//
+// Every file is read from the same file system. The project files, along with
+// the directories searched for resource files and any files that were looked
+// for but do not exist, are kept as paths, so that the project can be watched
+// for changes.
+type projectFiles struct {
+	fsys     fs.FS
+	files    resource.FilePaths
+	seen     map[resource.FilePath]bool
+	included map[string]bool
+	paths    []string
+	problems schema.Problems
+}
+
+// add validates the project file found at the given path within the file
+// system and adds the resource files that it loads, followed by those of each
+// project that it includes. The decoded config is returned, or false if the
+// project could not be decoded.
+func (pf *projectFiles) add(name string, cfg []byte) (Config, bool) {
+	var c Config
+	name = utils.CleanPath(name)
+	pf.included[name] = true
+	pf.paths = append(pf.paths, utils.DisplayPath(pf.fsys, name))
+
+	d, err := schema.NewDocument(utils.DisplayPath(pf.fsys, name), cfg)
+	if err != nil {
+		pf.problems = append(pf.problems, err.(schema.Problems)...)
+		return c, false
+	}
+
+	// Resource files are still validated when the project has problems, as
+	// long as it can be decoded. Once the project is valid, the url is the
+	// only thing that can fail to decode.
+	ps := d.ValidateProject()
+	if err := json.Unmarshal(d.JSON, &c); err != nil {
+		if len(ps) == 0 {
+			ps = schema.Problems{d.Problem(err.Error(), "", "project", "url")}
+		}
+		pf.problems = append(pf.problems, ps...)
+		return c, false
+	}
+
+	for i, rf := range c.Project.ResourceFiles {
+		for _, dir := range rf.Dirs(pf.fsys) {
+			pf.paths = append(pf.paths, utils.DisplayPath(pf.fsys, dir))
+		}
+		fps, err := rf.Expand(pf.fsys)
+		if err == nil && len(fps) == 0 {
+			err = pf.noResourceFiles(rf)
+		}
+		if err != nil {
+			if !rf.IsPattern() {
+				pf.paths = append(pf.paths,
+					utils.DisplayPath(pf.fsys, string(rf)))
+			}
+			ps = append(ps, d.Problem(err.Error(),
+				resource.LookedFor(pf.fsys, string(rf)),
+				"project", "resourceFiles", strconv.Itoa(i)))
+			continue
+		}
+		for _, fp := range fps {
+			if !pf.seen[fp] {
+				pf.seen[fp] = true
+				pf.files = append(pf.files, fp)
+			}
+		}
+	}
+
+	// Included projects are added once the problems within this project
+	// have been, so that problems are given in the order the files are read.
+	type include struct {
+		path string
+		cfg  []byte
+	}
+	var includes []include
+	for i, inc := range c.Project.Include {
+		p, b, err := pf.readIncludedProject(inc)
+		if err != nil {
+			pf.paths = append(pf.paths, utils.DisplayPath(pf.fsys, inc))
+			ps = append(ps, d.Problem(err.Error(), resource.LookedFor(pf.fsys, inc),
+				"project", "include", strconv.Itoa(i)))
+			continue
+		}
+		if p = utils.CleanPath(p); !pf.included[p] {
+			pf.included[p] = true
+			includes = append(includes, include{p, b})
+		}
+	}
+	ps.Sort()
+	pf.problems = append(pf.problems, ps...)
+
+	for _, inc := range includes {
+		pf.add(inc.path, inc.cfg)
+	}
+	return c, true
+}
+
+// watched returns the paths of every file and directory that the project was
+// read from, so that it can be reloaded when any of them change.
+func (pf *projectFiles) watched() []string {
+	paths := append([]string(nil), pf.paths...)
+	for _, fp := range pf.files {
+		paths = append(paths, utils.DisplayPath(pf.fsys, string(fp)))
+	}
+	return paths
+}
+
+// readIncludedProject reads the project file of an included project, which is
+// given relative to the root of the file system either as the directory that
+// the project is in or as the path of the project file itself.
+func (pf *projectFiles) readIncludedProject(inc string) (string, []byte, error) {
+	p := utils.CleanPath(inc)
+	if info, err := fs.Stat(pf.fsys, p); err == nil && !info.IsDir() {
+		b, err := fs.ReadFile(pf.fsys, p)
+		return p, b, err
+	}
+	name, b, err := readProjectFile(pf.fsys, p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil, fmt.Errorf("included project \"%s\" does not exist", inc)
+	}
+	return name, b, err
+}
+
+// noResourceFiles returns the problem with a resource file path that does not
+// match any files.
+func (pf *projectFiles) noResourceFiles(rf resource.FilePath) error {
+	if rf.IsPattern() {
+		return fmt.Errorf("no resource files match \"%s\"", rf)
+	}
+	p := utils.CleanPath(string(rf))
+	if info, err := fs.Stat(pf.fsys, p); err == nil && info.IsDir() {
+		return fmt.Errorf("directory \"%s\" has no resource files", rf)
+	}
+	return fmt.Errorf("resource file \"%s\" does not exist", rf)
+}
@@ -0,0 +1,104 @@
+package prompt
+
+import (
+	"sync"
+
+	"github.com/hazbo/httpu/utils/varparser"
+)
+
+// Prompt represents a value that has to be entered by the user each time a
+// request that references it is made.
+//
+// e.g. if a RequestSpec.Uri is set to /orders/${prompt[orderId]}, the user
+// will be asked for orderId before the request is sent, with the variable
+// being replaced with whatever was entered.
+type Prompt struct {
+	Name    string `json:"name"`
+	Message string `json:"message"`
+	Default string `json:"default"`
+	Secret  bool   `json:"secret"`
+}
+
+// Label returns the text that should be shown to the user when asking for a
+// value for the prompt.
+func (p Prompt) Label() string {
+	if p.Message != "" {
+		return p.Message
+	}
+	return p.Name
+}
+
+// Initial returns the value that the prompt should be filled with before the
+// user enters anything. This is the last value entered for the prompt within
+// the given map if there is one, and the default value otherwise.
+func (p Prompt) Initial(values *Map) string {
+	if values == nil {
+		return p.Default
+	}
+	if v, ok := values.Get(p.Name); ok {
+		return v
+	}
+	return p.Default
+}
+
+// Prompts represents multiple prompts.
+type Prompts []Prompt
+
+// Get returns the prompt configured with the given name. If no prompt has been
+// configured, a prompt with only the name set is returned, as any variable can
+// be used without being configured first.
+func (ps Prompts) Get(name string) Prompt {
+	for _, p := range ps {
+		if p.Name == name {
+			return p
+		}
+	}
+	return Prompt{Name: name}
+}
+
+// Map is a map of the last value entered for each prompt, referenced by name.
+// Each project being used has its own, held by the client it is loaded into.
+//
+// Values can be entered while requests are being made, so each map is guarded
+// by a lock of its own. The zero value is an empty map ready to use.
+type Map struct {
+	mu     sync.RWMutex
+	values map[string]string
+}
+
+// Replace is used to replace a variable with the last value entered for the
+// prompt.
+func (m *Map) Replace(k string) string {
+	v, _ := m.Get(k)
+	return v
+}
+
+// Get returns the last value entered for the prompt with the given name, or
+// false if nothing has been entered.
+func (m *Map) Get(name string) (string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	v, ok := m.values[name]
+	return v, ok
+}
+
+// Parse replaces each prompt variable within the given string with the value
+// held for it within the map.
+func (m *Map) Parse(s string) string {
+	return varparser.New("prompt").Parse(s, m)
+}
+
+// Set sets the value entered for the prompt with the given name.
+func (m *Map) Set(name, value string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.values == nil {
+		m.values = map[string]string{}
+	}
+	m.values[name] = value
+}
+
+// Names returns the name of each prompt variable found within the given string.
+func Names(s string) []string {
+	return varparser.New("prompt").Keys(s)
+}
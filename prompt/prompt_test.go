@@ -0,0 +1,33 @@
+package prompt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInitial(t *testing.T) {
+	m := &Map{}
+	p := Prompt{Name: "orderId", Default: "1"}
+	assert.Equal(t, "1", p.Initial(m))
+	assert.Equal(t, "1", p.Initial(nil))
+
+	m.Set("orderId", "2")
+	assert.Equal(t, "2", p.Initial(m))
+}
+
+func TestGet(t *testing.T) {
+	ps := Prompts{
+		Prompt{Name: "password", Secret: true},
+	}
+	assert.True(t, ps.Get("password").Secret)
+	assert.Equal(t, "other", ps.Get("other").Name)
+	assert.Equal(t, "other", ps.Get("other").Label())
+}
+
+func TestParse(t *testing.T) {
+	m := &Map{}
+	m.Set("orderId", "123")
+	assert.Equal(t, "/orders/123", m.Parse("/orders/${prompt[orderId]}"))
+	assert.Equal(t, []string{"orderId"}, Names("/orders/${prompt[orderId]}"))
+}
@@ -0,0 +1,153 @@
+package resource
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hazbo/httpu/resource/format"
+	utils "github.com/hazbo/httpu/utils/common"
+)
+
+// IsPattern checks whether the path is a glob pattern rather than the path of
+// a single file or directory.
+func (fp FilePath) IsPattern() bool {
+	return strings.ContainsAny(string(fp), "*?[")
+}
+
+// Expand returns the resource files within the file system that the path
+// refers to, relative to its root in the same way as the path itself. The path
+// can be that of a single file, of a directory, or a glob pattern, where **
+// matches any number of directories, such as requests/**/*.json.
+//
+// Directories and patterns only match files with a supported extension, found
+// within any directory below them other than hidden ones. Project files are
+// never matched, so that a directory can hold both a project and its requests.
+// Files are returned sorted by their path, so that they are always loaded in
+// the same order. No files are returned when nothing matches the path.
+func (fp FilePath) Expand(fsys fs.FS) (FilePaths, error) {
+	p := utils.CleanPath(string(fp))
+	if !fp.IsPattern() {
+		info, err := fs.Stat(fsys, p)
+		if err != nil {
+			return nil, nil
+		}
+		if !info.IsDir() {
+			return FilePaths{FilePath(p)}, nil
+		}
+		return walk(fsys, p, func(string) bool { return true })
+	}
+
+	if _, err := path.Match(p, ""); err != nil {
+		return nil, fmt.Errorf("invalid pattern \"%s\": %s", fp, err)
+	}
+
+	parts := strings.Split(p, "/")
+	return walk(fsys, patternBase(parts), func(name string) bool {
+		return match(parts, strings.Split(name, "/"))
+	})
+}
+
+// Dirs returns the directories within the file system that Expand looks for
+// files within, so that files being added to or removed from them can be
+// noticed.
+func (fp FilePath) Dirs(fsys fs.FS) []string {
+	p := utils.CleanPath(string(fp))
+	if fp.IsPattern() {
+		p = patternBase(strings.Split(p, "/"))
+	}
+	if info, err := fs.Stat(fsys, p); err != nil || !info.IsDir() {
+		return nil
+	}
+
+	var dirs []string
+	fs.WalkDir(fsys, p, func(d string, e fs.DirEntry, err error) error {
+		if err != nil || !e.IsDir() {
+			return nil
+		}
+		if d != p && strings.HasPrefix(e.Name(), ".") {
+			return fs.SkipDir
+		}
+		dirs = append(dirs, d)
+		return nil
+	})
+	return dirs
+}
+
+// patternBase returns the directory that comes before the first part of a
+// glob pattern containing a wildcard, which is the only one that needs to be
+// walked to find the files it matches.
+func patternBase(parts []string) string {
+	for i, part := range parts {
+		if FilePath(part).IsPattern() {
+			return path.Join(parts[:i]...)
+		}
+	}
+	return path.Join(parts...)
+}
+
+// walk returns the files with a supported extension within the directory of
+// the file system, or any directory below it, whose paths are matched by the
+// given function.
+func walk(
+	fsys fs.FS, dir string, matches func(string) bool) (FilePaths, error) {
+	if _, err := fs.Stat(fsys, dir); err != nil {
+		return nil, nil
+	}
+
+	var fps FilePaths
+	err := fs.WalkDir(fsys, dir, func(p string, e fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p != dir && strings.HasPrefix(e.Name(), ".") {
+			if e.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if e.IsDir() || !format.IsSupported(p) || isProjectFile(p) {
+			return nil
+		}
+		if matches(p) {
+			fps = append(fps, FilePath(p))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not read directory \"%s\": %s", dir, err)
+	}
+	sort.Slice(fps, func(i, j int) bool { return fps[i] < fps[j] })
+	return fps, nil
+}
+
+// match checks whether each part of a path is matched by each part of a glob
+// pattern, where a part that is ** matches any number of parts.
+func match(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(name); i++ {
+			if match(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(name) == 0 {
+		return false
+	}
+	ok, _ := path.Match(pattern[0], name[0])
+	return ok && match(pattern[1:], name[1:])
+}
+
+// isProjectFile checks whether the file is a project file, rather than a
+// resource file.
+func isProjectFile(p string) bool {
+	name := filepath.Base(p)
+	return strings.TrimSuffix(name, filepath.Ext(name)) == "project"
+}
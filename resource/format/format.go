@@ -0,0 +1,195 @@
+// Package format converts project and resource files written as YAML, TOML or
+// JSON with comments into plain JSON, so that they can be loaded in the same
+// way as any JSON file.
+package format
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Extensions are the extensions that project and resource files can have, in
+// the order that project files are looked for.
+var Extensions = []string{".json", ".jsonc", ".yaml", ".yml", ".toml"}
+
+// IsSupported checks whether the file has one of the supported extensions.
+func IsSupported(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, e := range Extensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// Error is an error found within a file while converting it into JSON. The
+// line and column are zero when they are not known.
+type Error struct {
+	Format  string
+	Line    int
+	Column  int
+	Message string
+}
+
+// Error returns the error message, without the position of the error.
+func (e *Error) Error() string {
+	return fmt.Sprintf("Invalid %s: %s", e.Format, e.Message)
+}
+
+// ToJSON converts the contents of a file into JSON, based on the extension of
+// its path. YAML and TOML are converted into the JSON equivalent, whereas JSON
+// can contain comments and trailing commas, which are removed. Files with any
+// other extension are read as JSON. Any error returned is an *Error.
+func ToJSON(path string, b []byte) ([]byte, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return yamlToJSON(b)
+	case ".toml":
+		return tomlToJSON(b)
+	}
+	return jsoncToJSON(b)
+}
+
+// jsoncToJSON removes any comments and trailing commas from JSON, checking
+// that what is left is valid.
+func jsoncToJSON(b []byte) ([]byte, error) {
+	j := StripJSONC(b)
+	var v interface{}
+	if err := json.Unmarshal(j, &v); err != nil {
+		e := &Error{Format: "JSON", Message: err.Error()}
+		if se, ok := err.(*json.SyntaxError); ok {
+			e.Line, e.Column = position(j, int(se.Offset)-1)
+		}
+		return nil, e
+	}
+	return j, nil
+}
+
+// yamlLine matches the line that YAML errors are found on.
+var yamlLine = regexp.MustCompile(`^line (\d+): `)
+
+// tomlLine matches the line, and last key, that TOML errors are found at.
+var tomlLine = regexp.MustCompile(`^toml: line \d+( \(last key "[^"]*"\))?: `)
+
+// yamlToJSON converts the first document within a YAML file into JSON.
+func yamlToJSON(b []byte) ([]byte, error) {
+	var v interface{}
+	if err := yaml.Unmarshal(b, &v); err != nil {
+		e := &Error{Format: "YAML"}
+		e.Message = strings.TrimPrefix(err.Error(), "yaml: ")
+		if te, ok := err.(*yaml.TypeError); ok {
+			e.Message = te.Errors[0]
+		}
+		if m := yamlLine.FindStringSubmatch(e.Message); m != nil {
+			e.Line, _ = strconv.Atoi(m[1])
+			e.Message = e.Message[len(m[0]):]
+		}
+		return nil, e
+	}
+	j, err := json.Marshal(jsonValue(v))
+	if err != nil {
+		return nil, &Error{Format: "YAML", Message: err.Error()}
+	}
+	return j, nil
+}
+
+// jsonValue converts a value decoded from YAML into one that can be encoded as
+// JSON, as mappings whose keys are not all strings are decoded with keys of any
+// type.
+func jsonValue(v interface{}) interface{} {
+	switch vv := v.(type) {
+	case map[string]interface{}:
+		for k, e := range vv {
+			vv[k] = jsonValue(e)
+		}
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(vv))
+		for k, e := range vv {
+			m[fmt.Sprint(k)] = jsonValue(e)
+		}
+		return m
+	case []interface{}:
+		for i, e := range vv {
+			vv[i] = jsonValue(e)
+		}
+	}
+	return v
+}
+
+// tomlToJSON converts a TOML file into JSON.
+func tomlToJSON(b []byte) ([]byte, error) {
+	var v map[string]interface{}
+	if err := toml.Unmarshal(b, &v); err != nil {
+		e := &Error{Format: "TOML", Message: err.Error()}
+		if pe, ok := err.(toml.ParseError); ok {
+			e.Message = tomlLine.ReplaceAllString(pe.Error(), "")
+			e.Line, e.Column = position(b, pe.Position.Start)
+		}
+		return nil, e
+	}
+	j, err := json.Marshal(v)
+	if err != nil {
+		return nil, &Error{Format: "TOML", Message: err.Error()}
+	}
+	return j, nil
+}
+
+// StripJSONC removes line and block comments, along with trailing commas, from
+// JSON. Each removed character is replaced with a space, keeping newlines, so
+// that the line and column of everything else stays the same.
+func StripJSONC(b []byte) []byte {
+	out := append([]byte(nil), b...)
+
+	// The position of the last comma outside of a string, which is removed if
+	// the next character other than whitespace or a comment closes an object
+	// or array.
+	comma := -1
+
+	for i := 0; i < len(out); i++ {
+		c := out[i]
+		switch {
+		case c == '"':
+			comma = -1
+			for i++; i < len(out) && out[i] != '"'; i++ {
+				if out[i] == '\\' {
+					i++
+				}
+			}
+		case c == '/' && i+1 < len(out) && out[i+1] == '/':
+			for ; i < len(out) && out[i] != '\n'; i++ {
+				out[i] = ' '
+			}
+		case c == '/' && i+1 < len(out) && out[i+1] == '*':
+			out[i], out[i+1] = ' ', ' '
+			for i += 2; i < len(out); i++ {
+				if out[i] == '*' && i+1 < len(out) && out[i+1] == '/' {
+					out[i], out[i+1] = ' ', ' '
+					i++
+					break
+				}
+				if out[i] != '\n' {
+					out[i] = ' '
+				}
+			}
+		case c == ',':
+			comma = i
+		case c == '}' || c == ']':
+			if comma >= 0 {
+				out[comma] = ' '
+			}
+			comma = -1
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+		default:
+			comma = -1
+		}
+	}
+	return out
+}
@@ -0,0 +1,80 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsSupported(t *testing.T) {
+	assert.True(t, IsSupported("requests/users.YML"))
+	assert.True(t, IsSupported("project.toml"))
+	assert.False(t, IsSupported("data/users.csv"))
+}
+
+func TestToJSON(t *testing.T) {
+	j, err := ToJSON("r.yaml", []byte("name: users\nspec:\n  port: 80\n  1: one\n"))
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"name": "users", "spec": {"port": 80, "1": "one"}}`,
+		string(j))
+
+	j, err = ToJSON("r.toml", []byte("name = \"users\"\n[spec]\nport = 80\n"))
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"name": "users", "spec": {"port": 80}}`, string(j))
+
+	_, err = ToJSON("r.yml", []byte("name: [users"))
+	assert.Contains(t, err.Error(), "Invalid YAML: ")
+
+	_, err = ToJSON("r.toml", []byte("name = "))
+	assert.Contains(t, err.Error(), "Invalid TOML: ")
+}
+
+func TestStripJSONC(t *testing.T) {
+	in := "{\n" +
+		"\t// The name of the request.\n" +
+		"\t\"name\": \"a // b /* c */\", /* inline */\n" +
+		"\t\"list\": [1, 2,],\n" +
+		"}\n"
+	out := StripJSONC([]byte(in))
+	assert.Len(t, out, len(in))
+	assert.JSONEq(t, `{"name": "a // b /* c */", "list": [1, 2]}`, string(out))
+
+	// Escaped quotes do not end a string.
+	out = StripJSONC([]byte(`{"a": "\"//", "b": 1,}`))
+	assert.JSONEq(t, `{"a": "\"//", "b": 1}`, string(out))
+}
+
+func TestLocate(t *testing.T) {
+	path := []string{"spec", "variants", "1", "method"}
+
+	line, col := Locate("r.json", []byte(`{
+	// Comments are skipped.
+	"spec": {"uri": "/x", "variants": [
+		{"name": "a", "data": {"fromFile": "a]}.json"}},
+		{"name": "b", "method": "PSOT"}
+	]}
+}`), path)
+	assert.Equal(t, []int{5, 17}, []int{line, col})
+
+	line, col = Locate("r.yaml", []byte(
+		"spec:\n  variants:\n    - name: a\n    - name: b\n      method: PSOT\n"),
+		path)
+	assert.Equal(t, []int{5, 7}, []int{line, col})
+
+	line, col = Locate("r.toml", []byte("[spec]\nuri = \"/x\"\n\n"+
+		"[[spec.variants]]\nname = \"a\"\n\n"+
+		"[[spec.variants]]\nname = \"b\"\nmethod = \"PSOT\"\n"), path)
+	assert.Equal(t, []int{9, 1}, []int{line, col})
+
+	line, _ = Locate("r.json", []byte(`{"spec": {}}`), path)
+	assert.Equal(t, 0, line)
+}
+
+func TestToJSONErrors(t *testing.T) {
+	_, err := ToJSON("r.json", []byte("{\n  \"name\" 1\n}"))
+	assert.Equal(t, &Error{Format: "JSON", Line: 2, Column: 10,
+		Message: "invalid character '1' after object key"}, err)
+
+	_, err = ToJSON("r.toml", []byte("kind = \"request\"\nname = \n"))
+	assert.Equal(t, 2, err.(*Error).Line)
+}
@@ -0,0 +1,257 @@
+package format
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Locate finds the line and column of a value within a file, given the path of
+// object keys and array indexes that lead to it, as used by JSON pointers. For
+// a value within an object, the position of its key is given instead. A line
+// of zero is returned if the value could not be found.
+//
+// Values within TOML files are found by looking for the lines that their keys
+// and tables are set on, so that only the closest of these is found for values
+// within inline tables and arrays.
+func Locate(path string, b []byte, ptr []string) (line, col int) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return locateYAML(b, ptr)
+	case ".toml":
+		return locateTOML(b, ptr)
+	}
+	j := StripJSONC(b)
+	s := jsonScanner{b: j}
+	if i := s.locate(ptr); i >= 0 {
+		return position(j, i)
+	}
+	return 0, 0
+}
+
+// position returns the line and column of the byte at the given offset, both
+// starting at 1.
+func position(b []byte, offset int) (line, col int) {
+	if offset > len(b) {
+		offset = len(b)
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	start := strings.LastIndexByte(string(b[:offset]), '\n') + 1
+	line = strings.Count(string(b[:offset]), "\n") + 1
+	return line, utf8.RuneCount(b[start:offset]) + 1
+}
+
+// jsonScanner finds the offset of values within JSON, which must be valid.
+type jsonScanner struct {
+	b []byte
+	i int
+}
+
+// space moves past any whitespace.
+func (s *jsonScanner) space() {
+	for s.i < len(s.b) && strings.IndexByte(" \t\r\n", s.b[s.i]) >= 0 {
+		s.i++
+	}
+}
+
+// next moves past the given delimiter if it is the next character.
+func (s *jsonScanner) next(c byte) {
+	s.space()
+	if s.i < len(s.b) && s.b[s.i] == c {
+		s.i++
+	}
+}
+
+// locate returns the offset of the value at the given path, starting from the
+// value at the current position, or -1 if it does not exist.
+func (s *jsonScanner) locate(ptr []string) int {
+	s.space()
+	if len(ptr) == 0 {
+		return s.i
+	}
+	if s.i >= len(s.b) {
+		return -1
+	}
+
+	switch s.b[s.i] {
+	case '{':
+		s.i++
+		for {
+			s.space()
+			if s.i >= len(s.b) || s.b[s.i] != '"' {
+				return -1
+			}
+			start := s.i
+			s.skip()
+			var key string
+			json.Unmarshal(s.b[start:s.i], &key)
+			s.next(':')
+			if key == ptr[0] {
+				if len(ptr) == 1 {
+					return start
+				}
+				return s.locate(ptr[1:])
+			}
+			s.space()
+			s.skip()
+			s.next(',')
+		}
+	case '[':
+		n, err := strconv.Atoi(ptr[0])
+		if err != nil {
+			return -1
+		}
+		s.i++
+		for i := 0; ; i++ {
+			s.space()
+			if s.i >= len(s.b) || s.b[s.i] == ']' {
+				return -1
+			}
+			if i == n {
+				return s.locate(ptr[1:])
+			}
+			start := s.i
+			s.skip()
+			s.next(',')
+			if s.i == start {
+				return -1
+			}
+		}
+	}
+	return -1
+}
+
+// skip moves past the value at the current position.
+func (s *jsonScanner) skip() {
+	depth := 0
+	for ; s.i < len(s.b); s.i++ {
+		switch s.b[s.i] {
+		case '"':
+			for s.i++; s.i < len(s.b) && s.b[s.i] != '"'; s.i++ {
+				if s.b[s.i] == '\\' {
+					s.i++
+				}
+			}
+			if depth == 0 {
+				s.i++
+				return
+			}
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+			if depth <= 0 {
+				if depth == 0 {
+					s.i++
+				}
+				return
+			}
+		case ',', ' ', '\t', '\r', '\n':
+			if depth == 0 {
+				return
+			}
+		}
+	}
+}
+
+// locateYAML finds a value within a YAML file using the nodes it is parsed
+// into, which hold their own positions.
+func locateYAML(b []byte, ptr []string) (line, col int) {
+	var n yaml.Node
+	if err := yaml.Unmarshal(b, &n); err != nil || len(n.Content) == 0 {
+		return 0, 0
+	}
+	node := n.Content[0]
+	for i, key := range ptr {
+		for node.Kind == yaml.AliasNode {
+			node = node.Alias
+		}
+		var next *yaml.Node
+		switch node.Kind {
+		case yaml.MappingNode:
+			for j := 0; j+1 < len(node.Content); j += 2 {
+				if node.Content[j].Value == key {
+					if i == len(ptr)-1 {
+						return node.Content[j].Line, node.Content[j].Column
+					}
+					next = node.Content[j+1]
+					break
+				}
+			}
+		case yaml.SequenceNode:
+			if k, err := strconv.Atoi(key); err == nil && k < len(node.Content) {
+				next = node.Content[k]
+			}
+		}
+		if next == nil {
+			return 0, 0
+		}
+		node = next
+	}
+	return node.Line, node.Column
+}
+
+// tomlTable matches the header of a table, or an array of tables, capturing
+// its name.
+var tomlTable = regexp.MustCompile(`^\[\[?\s*([^\]]+?)\s*\]\]?`)
+
+// locateTOML finds a value within a TOML file by looking for the line that
+// sets each key along the path in turn, starting from the line the previous
+// key was found on. Indexes find the table headers of arrays of tables.
+func locateTOML(b []byte, ptr []string) (line, col int) {
+	lines := strings.Split(string(b), "\n")
+	from, key := 0, ""
+	for _, p := range ptr {
+		if n, err := strconv.Atoi(p); err == nil {
+			for i := from; i < len(lines); i++ {
+				m := tomlTable.FindStringSubmatch(strings.TrimSpace(lines[i]))
+				if m == nil || !strings.HasPrefix(strings.TrimSpace(lines[i]), "[[") ||
+					!hasTOMLKey(m[1], key) {
+					continue
+				}
+				if n == 0 {
+					from, line = i, i+1
+					col = strings.Index(lines[i], "[") + 1
+					break
+				}
+				n--
+			}
+			continue
+		}
+		key = p
+		for i := from; i < len(lines); i++ {
+			t := strings.TrimSpace(lines[i])
+			name := t
+			if m := tomlTable.FindStringSubmatch(t); m != nil {
+				name = m[1]
+			} else if eq := strings.IndexByte(t, '='); eq > 0 {
+				name = t[:eq]
+			} else {
+				continue
+			}
+			if hasTOMLKey(name, p) {
+				from, line = i, i+1
+				col = len(lines[i]) - len(strings.TrimLeft(lines[i], " \t")) + 1
+				break
+			}
+		}
+	}
+	return line, col
+}
+
+// hasTOMLKey checks whether the dotted key, or table name, contains the key.
+func hasTOMLKey(name, key string) bool {
+	for _, k := range strings.Split(name, ".") {
+		if strings.Trim(strings.TrimSpace(k), `"'`) == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,193 @@
+package request
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/buger/jsonparser"
+)
+
+// Args represents the arguments that can be passed along with the name of a
+// request, either from the command bar or the run command, which apply to a
+// single execution of that request without the resource being modified.
+//
+// e.g. orders.create amount=10 -H X-Debug:1 ?page=2
+//
+// Fields given as name=value set a path parameter if the request has one by
+// that name, a form field if the request sends form data or a multipart body,
+// and a field within the JSON body otherwise. Nested fields within the body can
+// be set using a dot between each key, such as customer.name=value.
+type Args struct {
+	Fields  map[string]string
+	Headers http.Header
+	Query   url.Values
+}
+
+// ParseArgs parses each argument given after the name of a request.
+func ParseArgs(args []string) (Args, error) {
+	a := Args{
+		Fields:  map[string]string{},
+		Headers: http.Header{},
+		Query:   url.Values{},
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "":
+			continue
+		case arg == "-H":
+			if i+1 >= len(args) {
+				return Args{}, fmt.Errorf("-H expects a header as Name:Value.")
+			}
+			i++
+			hv := strings.SplitN(args[i], ":", 2)
+			if len(hv) != 2 || hv[0] == "" {
+				return Args{}, fmt.Errorf(
+					"Header \"%s\" must be in the format Name:Value.", args[i])
+			}
+			a.Headers.Add(hv[0], strings.TrimSpace(hv[1]))
+		case arg[0] == '?':
+			kv := strings.SplitN(arg[1:], "=", 2)
+			if kv[0] == "" {
+				return Args{}, fmt.Errorf(
+					"Query parameter \"%s\" must be in the format ?name=value.", arg)
+			}
+			if len(kv) == 1 {
+				kv = append(kv, "")
+			}
+			a.Query.Add(kv[0], kv[1])
+		default:
+			kv := strings.SplitN(arg, "=", 2)
+			if len(kv) != 2 || kv[0] == "" {
+				return Args{}, fmt.Errorf(
+					"Argument \"%s\" must be in the format name=value.", arg)
+			}
+			a.Fields[kv[0]] = kv[1]
+		}
+	}
+	return a, nil
+}
+
+// Apply applies the arguments to the given request, and to the variant if
+// there is one, taking precedence over anything set within the resource.
+func (a Args) Apply(r *Request, v *Variant) error {
+	pathParams := r.PathParams(v)
+
+	var (
+		pp   = &r.Spec.PathParams
+		fd   = &r.Spec.FormData
+		mp   = &r.Spec.Multipart
+		data = &r.Spec.Data
+		hs   = &r.Spec.Headers
+		q    = &r.Spec.Query
+	)
+	if v != nil {
+		pp, fd, mp = &v.PathParams, &v.FormData, &v.Multipart
+		data, hs, q = &v.Data, &v.Headers, &v.Query
+	}
+
+	for k, val := range a.Fields {
+		switch {
+		case containsString(pathParams, k):
+			if *pp == nil {
+				*pp = map[string]string{}
+			}
+			(*pp)[k] = val
+		case len(*mp) > 0:
+			mp.Set(k, val)
+		case len(*fd) > 0:
+			fd.Set(k, val)
+		case r.Spec.GraphQL != nil || (v != nil && v.GraphQL != nil):
+			if err := graphQLFor(r, v).setVariable(k, val); err != nil {
+				return err
+			}
+		case r.Spec.GRPC != nil && (v == nil || len(v.Message) == 0):
+			if err := grpcFor(r, v).setField(k, val); err != nil {
+				return err
+			}
+		case v != nil && len(v.Message) > 0:
+			b, err := setJSONField(v.Message, k, val)
+			if err != nil {
+				return fmt.Errorf("Could not set field \"%s\" in message: %s", k, err)
+			}
+			v.Message = b
+		default:
+			if err := data.setField(k, val); err != nil {
+				return err
+			}
+		}
+	}
+
+	for k, val := range a.Headers {
+		if *hs == nil {
+			*hs = http.Header{}
+		}
+		// The headers of the request are added to those of the variant when
+		// it is made, so the header is removed from the request to make sure
+		// that it is overridden rather than sent twice.
+		r.Spec.Headers.Del(k)
+		(*hs)[k] = val
+	}
+
+	for k, val := range a.Query {
+		if *q == nil {
+			*q = url.Values{}
+		}
+		(*q)[k] = val
+	}
+	return nil
+}
+
+// graphQLFor returns the query whose variables are sent for the request, and
+// the variant if there is one.
+func graphQLFor(r *Request, v *Variant) *GraphQL {
+	if v != nil && v.GraphQL != nil &&
+		(len(v.GraphQL.Variables) > 0 || r.Spec.GraphQL == nil) {
+		return v.GraphQL
+	}
+	return r.Spec.GraphQL
+}
+
+// grpcFor returns the method whose message is sent for the request, and the
+// variant if there is one.
+func grpcFor(r *Request, v *Variant) *GRPC {
+	if v != nil && v.GRPC != nil && len(v.GRPC.Message) > 0 {
+		return v.GRPC
+	}
+	return r.Spec.GRPC
+}
+
+// setField sets a field within the JSON request body.
+func (rd *requestData) setField(name, value string) error {
+	if rd.Binary {
+		return fmt.Errorf(
+			"Could not set field \"%s\", request data is binary.", name)
+	}
+
+	b, err := setJSONField(rd.contents, name, value)
+	if err != nil {
+		return fmt.Errorf("Could not set field \"%s\" in request data: %s", name, err)
+	}
+	rd.contents = b
+	return nil
+}
+
+// setJSONField sets a field within a JSON object, where the name can be a
+// dotted path to a nested field. Values that are valid JSON, such as numbers
+// and booleans, are set as they are, with anything else being set as a string.
+func setJSONField(body []byte, name, value string) ([]byte, error) {
+	if strings.TrimSpace(string(body)) == "" {
+		body = []byte("{}")
+	}
+
+	jv := []byte(value)
+	if !json.Valid(jv) {
+		jv, _ = json.Marshal(value)
+	}
+
+	return jsonparser.Set(body, jv, strings.Split(name, ".")...)
+}
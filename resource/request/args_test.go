@@ -0,0 +1,65 @@
+package request
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseArgs(t *testing.T) {
+	a, err := ParseArgs([]string{
+		"orderId=123", "note=a=b", "-H", "X-Debug:1", "?page=2", "?tag=a", "?tag=b"})
+	assert.Nil(t, err)
+	assert.Equal(t, "123", a.Fields["orderId"])
+	assert.Equal(t, "a=b", a.Fields["note"])
+	assert.Equal(t, "1", a.Headers.Get("X-Debug"))
+	assert.Equal(t, "2", a.Query.Get("page"))
+	assert.Equal(t, []string{"a", "b"}, a.Query["tag"])
+
+	_, err = ParseArgs([]string{"orderId"})
+	assert.NotNil(t, err)
+
+	_, err = ParseArgs([]string{"-H"})
+	assert.NotNil(t, err)
+}
+
+func TestArgsApply(t *testing.T) {
+	r := Request{
+		Spec: RequestSpec{
+			Uri:        "/orders/{orderId}",
+			PathParams: map[string]string{"orderId": "1"},
+			Variants: Variants{
+				Variant{
+					Name: "item",
+					Path: "/items/{itemId}",
+					Data: requestData{contents: []byte(`{"amount": 1}`)},
+				},
+			},
+		},
+	}
+	a, _ := ParseArgs([]string{
+		"itemId=2", "amount=10", "customer.name=bob", "-H", "X-Debug:1", "?page=2"})
+	err := a.Apply(&r, &r.Spec.Variants[0])
+	assert.Nil(t, err)
+
+	v := r.Spec.Variants[0]
+	assert.Equal(t, "2", v.PathParams["itemId"])
+	assert.Equal(t, `{"amount": 10,"customer":{"name":"bob"}}`, v.Data.String())
+	assert.Equal(t, "1", v.Headers.Get("X-Debug"))
+	assert.Equal(t, "2", v.Query.Get("page"))
+	assert.Equal(t, []string{"orderId", "itemId"}, r.PathParams(&v))
+}
+
+func TestArgsApplyFormData(t *testing.T) {
+	r := Request{
+		Spec: RequestSpec{
+			FormData: url.Values{"amount": {"1"}},
+		},
+	}
+	a, _ := ParseArgs([]string{"amount=10"})
+	a.Apply(&r, nil)
+
+	assert.Equal(t, "10", r.Spec.FormData.Get("amount"))
+	assert.Equal(t, "", r.Spec.Data.String())
+}
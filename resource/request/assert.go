@@ -0,0 +1,197 @@
+package request
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/buger/jsonparser"
+)
+
+// Assertion is a check made against the response to a request. Each assertion
+// checks one of the status code, a header, or the value at a JSON path within
+// the body, with the whole body being checked if none of them are set.
+//
+// Status is checked on its own. Anything else is checked using whichever of
+// Equals, Contains, Matches and Exists are set, where Equals can be any JSON
+// value and Matches is a regular expression. If none of them are set, the
+// header or value only needs to exist.
+type Assertion struct {
+	Status   int         `json:"status"`
+	Header   string      `json:"header"`
+	JsonPath []string    `json:"jsonPath"`
+	Equals   interface{} `json:"equals"`
+	Contains string      `json:"contains"`
+	Matches  string      `json:"matches"`
+	Exists   *bool       `json:"exists"`
+}
+
+// Assertions represents multiple assertions, which are all checked.
+type Assertions []Assertion
+
+// AssertionResult is the result of checking an assertion against a response,
+// where Err is why it failed, or nil if it passed.
+type AssertionResult struct {
+	Assertion Assertion
+	Err       error
+}
+
+// Passed checks whether the assertion passed.
+func (ar AssertionResult) Passed() bool {
+	return ar.Err == nil
+}
+
+// subject returns what the assertion checks, as it is shown in results.
+func (a Assertion) subject() string {
+	switch {
+	case a.Header != "":
+		return fmt.Sprintf("header %s", a.Header)
+	case len(a.JsonPath) > 0:
+		return fmt.Sprintf("jsonPath %s", strings.Join(a.JsonPath, "."))
+	}
+	return "body"
+}
+
+// String describes what the assertion checks, such as `status is 200`.
+func (a Assertion) String() string {
+	if a.Status != 0 {
+		return fmt.Sprintf("status is %d", a.Status)
+	}
+	var checks []string
+	if a.Equals != nil {
+		checks = append(checks, fmt.Sprintf("equals %s", a.expected()))
+	}
+	if a.Contains != "" {
+		checks = append(checks, fmt.Sprintf("contains %q", a.Contains))
+	}
+	if a.Matches != "" {
+		checks = append(checks, fmt.Sprintf("matches %q", a.Matches))
+	}
+	if len(checks) == 0 && a.Exists != nil && !*a.Exists {
+		checks = append(checks, "does not exist")
+	}
+	if len(checks) == 0 {
+		checks = append(checks, "exists")
+	}
+	return fmt.Sprintf("%s %s", a.subject(), strings.Join(checks, " and "))
+}
+
+// equals returns the value that Equals expects, as it is compared, with any
+// value other than a string being compared as JSON.
+func (a Assertion) equals() string {
+	if s, ok := a.Equals.(string); ok {
+		return s
+	}
+	b, _ := json.Marshal(a.Equals)
+	return string(b)
+}
+
+// expected returns the value that Equals expects, as it is shown, with strings
+// being quoted.
+func (a Assertion) expected() string {
+	if _, ok := a.Equals.(string); ok {
+		return fmt.Sprintf("%q", a.equals())
+	}
+	return a.equals()
+}
+
+// Check checks the assertion against the response and its body, returning why
+// it failed, or nil if it passed.
+func (a Assertion) Check(resp *http.Response, body []byte) error {
+	if a.Status != 0 {
+		if resp.StatusCode != a.Status {
+			return fmt.Errorf(
+				"status is %d, expected %d", resp.StatusCode, a.Status)
+		}
+		return nil
+	}
+
+	value, exists := string(body), true
+	switch {
+	case a.Header != "":
+		vs, ok := resp.Header[http.CanonicalHeaderKey(a.Header)]
+		value, exists = strings.Join(vs, ","), ok
+	case len(a.JsonPath) > 0:
+		v, dt, _, err := jsonparser.Get(body, a.JsonPath...)
+		value, exists = jsonValue(v, dt), err == nil
+	}
+
+	if a.Exists != nil && !*a.Exists {
+		if exists {
+			return fmt.Errorf("%s exists, expected it not to", a.subject())
+		}
+		return nil
+	}
+	if !exists {
+		return fmt.Errorf("%s does not exist", a.subject())
+	}
+
+	if a.Equals != nil && value != a.equals() {
+		return fmt.Errorf("%s is %q, expected %s",
+			a.subject(), value, a.expected())
+	}
+	if a.Contains != "" && !strings.Contains(value, a.Contains) {
+		return fmt.Errorf("%s is %q, expected it to contain %q",
+			a.subject(), value, a.Contains)
+	}
+	if a.Matches != "" {
+		re, err := regexp.Compile(a.Matches)
+		if err != nil {
+			return fmt.Errorf("invalid matches %q: %s", a.Matches, err)
+		}
+		if !re.MatchString(value) {
+			return fmt.Errorf("%s is %q, expected it to match %q",
+				a.subject(), value, a.Matches)
+		}
+	}
+	return nil
+}
+
+// jsonValue returns a value found within JSON as it is compared, with strings
+// unquoted and anything else compacted.
+func jsonValue(v []byte, dt jsonparser.ValueType) string {
+	if dt == jsonparser.String {
+		if s, err := jsonparser.ParseString(v); err == nil {
+			return s
+		}
+		return string(v)
+	}
+	var b bytes.Buffer
+	if err := json.Compact(&b, v); err != nil {
+		return string(v)
+	}
+	return b.String()
+}
+
+// Check checks each of the assertions against the response. The body is read
+// and replaced, so that it can still be read once the assertions have been
+// checked.
+func (as Assertions) Check(resp *http.Response) []AssertionResult {
+	if len(as) == 0 {
+		return nil
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	results := make([]AssertionResult, len(as))
+	for i, a := range as {
+		results[i] = AssertionResult{Assertion: a, Err: a.Check(resp, body)}
+	}
+	return results
+}
+
+// Failed returns the results of the assertions that failed.
+func Failed(results []AssertionResult) []AssertionResult {
+	var failed []AssertionResult
+	for _, r := range results {
+		if !r.Passed() {
+			failed = append(failed, r)
+		}
+	}
+	return failed
+}
@@ -0,0 +1,108 @@
+package request
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssertionCheck(t *testing.T) {
+	resp := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+	}
+	body := []byte(`{"user": {"id": 1, "name": "test", "roles": ["admin"]}}`)
+	no := false
+
+	for _, tc := range []struct {
+		a   Assertion
+		err string
+	}{
+		{Assertion{Status: 200}, ""},
+		{Assertion{Status: 201}, "status is 200, expected 201"},
+		{Assertion{Header: "content-type", Contains: "json"}, ""},
+		{Assertion{Header: "X-Missing"}, "header X-Missing does not exist"},
+		{Assertion{Header: "X-Missing", Exists: &no}, ""},
+		{Assertion{JsonPath: []string{"user", "id"}, Equals: 1.0}, ""},
+		{Assertion{JsonPath: []string{"user", "name"}, Equals: "test"}, ""},
+		{Assertion{JsonPath: []string{"user", "roles"}, Equals: []interface{}{"admin"}}, ""},
+		{Assertion{JsonPath: []string{"user", "name"}, Equals: "other"},
+			`jsonPath user.name is "test", expected "other"`},
+		{Assertion{JsonPath: []string{"user", "name"}, Matches: "^t.st$"}, ""},
+		{Assertion{JsonPath: []string{"user", "id"}, Exists: &no},
+			"jsonPath user.id exists, expected it not to"},
+		{Assertion{Contains: "admin"}, ""},
+	} {
+		err := tc.a.Check(resp, body)
+		if tc.err == "" {
+			assert.Nil(t, err, tc.a.String())
+			continue
+		}
+		if assert.NotNil(t, err, tc.a.String()) {
+			assert.Equal(t, tc.err, err.Error())
+		}
+	}
+}
+
+func TestAssertionString(t *testing.T) {
+	assert.Equal(t, "status is 200", Assertion{Status: 200}.String())
+	assert.Equal(t, `header Content-Type contains "json"`,
+		Assertion{Header: "Content-Type", Contains: "json"}.String())
+	assert.Equal(t, "jsonPath user.id equals 1",
+		Assertion{JsonPath: []string{"user", "id"}, Equals: 1.0}.String())
+	assert.Equal(t, "body exists", Assertion{}.String())
+}
+
+func TestMakeAssertionsAndTimeout(t *testing.T) {
+	teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/assert", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, fixture())
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	})
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	r := Request{Kind: "request", Name: "assert", Spec: RequestSpec{
+		Uri:     "/assert",
+		Method:  "GET",
+		Timeout: "5s",
+		Assertions: Assertions{
+			{Status: 200},
+			{JsonPath: []string{"error"}, Equals: true},
+		},
+	}}
+	resp, stat, err := r.Make(*u)
+	assert.Nil(t, err)
+	assert.Len(t, stat.Assertions, 2)
+	assert.True(t, stat.Assertions[0].Passed())
+	assert.Len(t, Failed(stat.Assertions), 1)
+
+	b, _ := ioutil.ReadAll(resp.Body)
+	assert.Equal(t, fixture(), string(b),
+		"the body can still be read once the assertions have been checked")
+
+	r.Spec.Uri, r.Spec.Timeout = "/slow", "50ms"
+	_, _, err = r.Make(*u)
+	assert.NotNil(t, err, "the request should time out")
+
+	r.Spec.Timeout = "soon"
+	_, _, err = r.Make(*u)
+	if assert.NotNil(t, err) {
+		assert.True(t, strings.Contains(err.Error(), "invalid timeout"))
+	}
+}
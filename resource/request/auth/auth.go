@@ -0,0 +1,215 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/hazbo/httpu/resource/request/headers"
+	"github.com/hazbo/httpu/stash"
+)
+
+// Type is the authentication scheme used for a request.
+type Type = string
+
+const (
+	Basic  Type = "basic"
+	Bearer Type = "bearer"
+	Digest Type = "digest"
+	APIKey Type = "apiKey"
+	OAuth2 Type = "oauth2"
+)
+
+const (
+	// InHeader sends an API key as a request header, which is the default.
+	InHeader = "header"
+
+	// InQuery sends an API key as a query parameter.
+	InQuery = "query"
+)
+
+// Auth represents the authentication used for a request. It can be set for the
+// whole project, a request or a variant, with the most specific one being used.
+// Credentials would usually be given using variables, such as ${env[TOKEN]},
+// so that they are not stored within the resource files.
+type Auth struct {
+	Type     Type   `json:"type"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Token    string `json:"token"`
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	In       string `json:"in"`
+
+	// OAuth2 holds the settings used to obtain a token for oauth2 auth.
+	OAuth2 *OAuth2Config `json:"oauth2"`
+
+	// Stash is where tokens obtained for oauth2 auth are cached, without which
+	// no tokens can be obtained.
+	Stash *stash.Map `json:"-"`
+
+	// TokenClient returns the client that oauth2 tokens are obtained from the
+	// given token url with, so that they are obtained in the same way as the
+	// request is made, such as through the same proxy. A client using the
+	// default transport is used if it is not set.
+	TokenClient func(tokenURL *url.URL) (*http.Client, error) `json:"-"`
+}
+
+// tokenClient returns the client that tokens are obtained from the given token
+// url with.
+func (a Auth) tokenClient(tokenURL *url.URL) (*http.Client, error) {
+	if a.TokenClient != nil {
+		return a.TokenClient(tokenURL)
+	}
+	return &http.Client{Timeout: tokenTimeout}, nil
+}
+
+// String returns the scheme used and who for, as shown in the request view.
+// Credentials themselves are never included.
+func (a Auth) String() string {
+	switch a.Type {
+	case Basic:
+		return fmt.Sprintf("Basic (username: %s)", a.Username)
+	case Bearer:
+		return "Bearer"
+	case Digest:
+		return fmt.Sprintf("Digest (username: %s)", a.Username)
+	case APIKey:
+		return fmt.Sprintf("API key (%s: %s)", a.in(), a.Name)
+	case OAuth2:
+		if a.OAuth2 == nil {
+			return "OAuth2"
+		}
+		// The default stash name is only known once any variables have been
+		// replaced, so only a configured one is shown.
+		if a.OAuth2.Stash == "" {
+			return fmt.Sprintf("OAuth2 (%s)", a.OAuth2.grant())
+		}
+		return fmt.Sprintf("OAuth2 (%s, stash: %s)", a.OAuth2.grant(), a.OAuth2.Stash)
+	}
+	return a.Type
+}
+
+// Strings returns each string within the auth that may contain variables.
+func (a Auth) Strings() []string {
+	ss := []string{a.Username, a.Password, a.Token, a.Name, a.Value}
+	if a.OAuth2 != nil {
+		ss = append(ss, a.OAuth2.strings()...)
+	}
+	return ss
+}
+
+// Parse replaces any variables within the auth using the given parser.
+func (a *Auth) Parse(parse func(string) string) {
+	a.Username = parse(a.Username)
+	a.Password = parse(a.Password)
+	a.Token = parse(a.Token)
+	a.Name = parse(a.Name)
+	a.Value = parse(a.Value)
+	if a.OAuth2 != nil {
+		c := *a.OAuth2
+		c.parse(parse)
+		a.OAuth2 = &c
+	}
+}
+
+// in returns where an API key is sent.
+func (a Auth) in() string {
+	if a.In == "" {
+		return InHeader
+	}
+	return a.In
+}
+
+// Apply applies the auth to a request before it is sent. Digest auth can only
+// be applied once the challenge has been received from the server, which is
+// done using Answer.
+func (a Auth) Apply(req *http.Request) error {
+	switch a.Type {
+	case Basic:
+		req.SetBasicAuth(a.Username, a.Password)
+	case Bearer:
+		req.Header.Set(headers.Authorization, fmt.Sprintf("Bearer %s", a.Token))
+	case Digest:
+	case APIKey:
+		if a.Name == "" {
+			return fmt.Errorf("API key auth requires a name.")
+		}
+		switch a.in() {
+		case InHeader:
+			req.Header.Set(a.Name, a.Value)
+		case InQuery:
+			q := req.URL.Query()
+			q.Set(a.Name, a.Value)
+			req.URL.RawQuery = q.Encode()
+		default:
+			return fmt.Errorf(
+				"API key auth can only be sent in a header or query, not \"%s\".",
+				a.In)
+		}
+	case OAuth2:
+		if a.OAuth2 == nil {
+			return fmt.Errorf("OAuth2 auth requires an oauth2 block.")
+		}
+		t, err := a.OAuth2.token(a, req.URL, false)
+		if err != nil {
+			return err
+		}
+		req.Header.Set(headers.Authorization, fmt.Sprintf("Bearer %s", t))
+	default:
+		return fmt.Errorf("Unsupported auth type \"%s\".", a.Type)
+	}
+	return nil
+}
+
+// Challenged returns true if the response is a challenge that the auth can
+// answer, as is the case for digest auth when the server responds with a 401.
+// A 401 when using oauth2 auth is taken as the token no longer being valid.
+func (a Auth) Challenged(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusUnauthorized {
+		return false
+	}
+	switch a.Type {
+	case Digest:
+		return digestChallenge(resp) != ""
+	case OAuth2:
+		return a.OAuth2 != nil
+	}
+	return false
+}
+
+// Answer sets the answer to the challenge sent in the response on the request,
+// which then needs to be sent again.
+func (a Auth) Answer(req *http.Request, resp *http.Response) error {
+	if a.Type == OAuth2 {
+		t, err := a.OAuth2.token(a, req.URL, true)
+		if err != nil {
+			return err
+		}
+		req.Header.Set(headers.Authorization, fmt.Sprintf("Bearer %s", t))
+		return nil
+	}
+
+	c := digestChallenge(resp)
+	if c == "" {
+		return fmt.Errorf("No digest challenge was sent by the server.")
+	}
+	h, err := digestAuthorization(a.Username, a.Password, req, c)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(headers.Authorization, h)
+	return nil
+}
+
+// digestChallenge returns the digest challenge sent in the response, if there
+// is one.
+func digestChallenge(resp *http.Response) string {
+	for _, c := range resp.Header[http.CanonicalHeaderKey("WWW-Authenticate")] {
+		if strings.HasPrefix(strings.ToLower(c), "digest ") {
+			return c
+		}
+	}
+	return ""
+}
@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		auth   Auth
+		header string
+		value  string
+		query  string
+	}{
+		{Auth{Type: Basic, Username: "bob", Password: "secret"},
+			"Authorization", "Basic Ym9iOnNlY3JldA==", ""},
+		{Auth{Type: Bearer, Token: "abc"}, "Authorization", "Bearer abc", ""},
+		{Auth{Type: APIKey, Name: "X-Api-Key", Value: "abc"}, "X-Api-Key", "abc", ""},
+		{Auth{Type: APIKey, Name: "key", Value: "abc", In: InQuery}, "", "", "key=abc"},
+	}
+
+	for _, tt := range tests {
+		req, _ := http.NewRequest("GET", "http://localhost/", nil)
+		assert.Nil(t, tt.auth.Apply(req))
+		if tt.header != "" {
+			assert.Equal(t, tt.value, req.Header.Get(tt.header))
+		}
+		assert.Equal(t, tt.query, req.URL.RawQuery)
+	}
+
+	req, _ := http.NewRequest("GET", "http://localhost/", nil)
+	assert.NotNil(t, Auth{Type: "nope"}.Apply(req))
+	assert.NotNil(t, Auth{Type: APIKey, Name: "k", In: "body"}.Apply(req))
+}
+
+func TestString(t *testing.T) {
+	a := Auth{Type: Basic, Username: "bob", Password: "secret"}
+	assert.Equal(t, "Basic (username: bob)", a.String())
+	assert.Equal(t, "Bearer", Auth{Type: Bearer, Token: "secret"}.String())
+	assert.Equal(t, "API key (header: X-Api-Key)",
+		Auth{Type: APIKey, Name: "X-Api-Key", Value: "secret"}.String())
+}
+
+func TestParseChallenge(t *testing.T) {
+	p := parseChallenge(
+		`realm="test@example.com", qop="auth,auth-int", algorithm=MD5, nonce="abc"`)
+	assert.Equal(t, "test@example.com", p["realm"])
+	assert.Equal(t, "auth,auth-int", p["qop"])
+	assert.Equal(t, "MD5", p["algorithm"])
+	assert.Equal(t, "abc", p["nonce"])
+}
+
+func TestDigest(t *testing.T) {
+	md5hex := func(s string) string {
+		h := md5.Sum([]byte(s))
+		return hex.EncodeToString(h[:])
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			h := r.Header.Get("Authorization")
+			if h == "" {
+				w.Header().Set("WWW-Authenticate",
+					`Digest realm="test", qop="auth", nonce="n0nce", opaque="op"`)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			p := parseChallenge(h[len("Digest "):])
+			ha1 := md5hex("bob:test:secret")
+			ha2 := md5hex(fmt.Sprintf("%s:%s", r.Method, p["uri"]))
+			expected := md5hex(fmt.Sprintf("%s:n0nce:%s:%s:auth:%s",
+				ha1, p["nc"], p["cnonce"], ha2))
+			if p["response"] != expected || p["opaque"] != "op" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}))
+	defer server.Close()
+
+	a := Auth{Type: Digest, Username: "bob", Password: "secret"}
+
+	req, _ := http.NewRequest("GET", server.URL+"/path?q=1", nil)
+	assert.Nil(t, a.Apply(req))
+	resp, err := http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.True(t, a.Challenged(resp))
+
+	req, _ = http.NewRequest("GET", server.URL+"/path?q=1", nil)
+	assert.Nil(t, a.Answer(req, resp))
+	resp, err = http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
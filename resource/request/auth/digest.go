@@ -0,0 +1,133 @@
+package auth
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"strings"
+)
+
+// digestAuthorization creates the Authorization header answering the given
+// digest challenge, as described in RFC 7616. Only the "auth" quality of
+// protection is supported.
+func digestAuthorization(
+	username, password string, req *http.Request, challenge string) (string, error) {
+
+	params := parseChallenge(challenge[len("digest "):])
+
+	var h func() hash.Hash
+	switch strings.ToUpper(params["algorithm"]) {
+	case "", "MD5", "MD5-SESS":
+		h = md5.New
+	case "SHA-256", "SHA-256-SESS":
+		h = sha256.New
+	default:
+		return "", fmt.Errorf(
+			"Unsupported digest algorithm \"%s\".", params["algorithm"])
+	}
+
+	digest := func(s string) string {
+		d := h()
+		d.Write([]byte(s))
+		return hex.EncodeToString(d.Sum(nil))
+	}
+
+	cnonce, err := newCnonce()
+	if err != nil {
+		return "", err
+	}
+
+	const nc = "00000001"
+	uri := req.URL.RequestURI()
+
+	ha1 := digest(fmt.Sprintf("%s:%s:%s", username, params["realm"], password))
+	if strings.HasSuffix(strings.ToUpper(params["algorithm"]), "-SESS") {
+		ha1 = digest(fmt.Sprintf("%s:%s:%s", ha1, params["nonce"], cnonce))
+	}
+	ha2 := digest(fmt.Sprintf("%s:%s", req.Method, uri))
+
+	var qop string
+	for _, q := range strings.Split(params["qop"], ",") {
+		if strings.TrimSpace(q) == "auth" {
+			qop = "auth"
+		}
+	}
+	if params["qop"] != "" && qop == "" {
+		return "", fmt.Errorf("Unsupported digest qop \"%s\".", params["qop"])
+	}
+
+	var response string
+	if qop == "" {
+		response = digest(fmt.Sprintf("%s:%s:%s", ha1, params["nonce"], ha2))
+	} else {
+		response = digest(fmt.Sprintf("%s:%s:%s:%s:%s:%s",
+			ha1, params["nonce"], nc, cnonce, qop, ha2))
+	}
+
+	parts := []string{
+		fmt.Sprintf(`username="%s"`, username),
+		fmt.Sprintf(`realm="%s"`, params["realm"]),
+		fmt.Sprintf(`nonce="%s"`, params["nonce"]),
+		fmt.Sprintf(`uri="%s"`, uri),
+		fmt.Sprintf(`response="%s"`, response),
+	}
+	if params["algorithm"] != "" {
+		parts = append(parts, fmt.Sprintf("algorithm=%s", params["algorithm"]))
+	}
+	if params["opaque"] != "" {
+		parts = append(parts, fmt.Sprintf(`opaque="%s"`, params["opaque"]))
+	}
+	if qop != "" {
+		parts = append(parts,
+			fmt.Sprintf("qop=%s", qop),
+			fmt.Sprintf("nc=%s", nc),
+			fmt.Sprintf(`cnonce="%s"`, cnonce))
+	}
+	return "Digest " + strings.Join(parts, ", "), nil
+}
+
+// parseChallenge parses the comma separated key="value" pairs of a challenge.
+func parseChallenge(s string) map[string]string {
+	params := map[string]string{}
+	for len(s) > 0 {
+		s = strings.TrimLeft(s, " ,")
+		eq := strings.IndexByte(s, '=')
+		if eq < 0 {
+			break
+		}
+		k := strings.ToLower(strings.TrimSpace(s[:eq]))
+		s = s[eq+1:]
+
+		var v string
+		if strings.HasPrefix(s, `"`) {
+			end := strings.IndexByte(s[1:], '"')
+			if end < 0 {
+				v, s = s[1:], ""
+			} else {
+				v, s = s[1:end+1], s[end+2:]
+			}
+		} else {
+			end := strings.IndexByte(s, ',')
+			if end < 0 {
+				v, s = s, ""
+			} else {
+				v, s = s[:end], s[end:]
+			}
+		}
+		params[k] = strings.TrimSpace(v)
+	}
+	return params
+}
+
+// newCnonce creates a random client nonce.
+func newCnonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,226 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hazbo/httpu/resource/request/headers"
+	"github.com/hazbo/httpu/stash"
+)
+
+// Grant is the OAuth2 grant used to obtain a token.
+type Grant = string
+
+const (
+	ClientCredentials Grant = "clientCredentials"
+	Password          Grant = "password"
+	RefreshToken      Grant = "refreshToken"
+)
+
+const (
+	// defaultTokenStash is the start of the name of the stash value that tokens
+	// are stored in, unless another is configured.
+	defaultTokenStash = "oauth2Token"
+
+	// expirySkew is how long before a token expires that it is refreshed, so
+	// that it does not expire while a request is being made.
+	expirySkew = 10 * time.Second
+
+	// tokenTimeout is how long obtaining a token can take.
+	tokenTimeout = 30 * time.Second
+)
+
+// OAuth2Config represents the settings used to obtain an OAuth2 access token
+// from a token endpoint. The username and password of the auth are used for
+// the password grant.
+//
+// Tokens are cached in the stash under the name given by Stash, so that they
+// can also be used as ${stash[name]}, with any refresh token being stored with
+// a "Refresh" suffix. Without a name, tokens are cached under one made from the
+// token url, client id and scopes, such as oauth2Token-1a2b3c4d.
+type OAuth2Config struct {
+	TokenURL     string   `json:"tokenUrl"`
+	Grant        Grant    `json:"grant"`
+	ClientID     string   `json:"clientId"`
+	ClientSecret string   `json:"clientSecret"`
+	Scopes       []string `json:"scopes"`
+	RefreshToken string   `json:"refreshToken"`
+	Stash        string   `json:"stash"`
+}
+
+// tokenResponse represents a successful response from a token endpoint.
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// grant returns the grant used, which defaults to client credentials.
+func (c OAuth2Config) grant() Grant {
+	if c.Grant == "" {
+		return ClientCredentials
+	}
+	return c.Grant
+}
+
+// stash returns the name of the stash value that the token is cached in. The
+// default name is made from the token url, client id and scopes, so that
+// requests obtaining tokens in different ways never use each other's tokens.
+func (c OAuth2Config) stash() string {
+	if c.Stash != "" {
+		return c.Stash
+	}
+	h := sha256.New()
+	for _, s := range append([]string{c.TokenURL, c.ClientID}, c.Scopes...) {
+		io.WriteString(h, s)
+		h.Write([]byte{0})
+	}
+	return fmt.Sprintf("%s-%x", defaultTokenStash, h.Sum(nil)[:4])
+}
+
+// strings returns each string within the config that may contain variables.
+func (c OAuth2Config) strings() []string {
+	return append([]string{
+		c.TokenURL, c.ClientID, c.ClientSecret, c.RefreshToken}, c.Scopes...)
+}
+
+// parse replaces any variables within the config using the given parser.
+func (c *OAuth2Config) parse(parse func(string) string) {
+	c.TokenURL = parse(c.TokenURL)
+	c.ClientID = parse(c.ClientID)
+	c.ClientSecret = parse(c.ClientSecret)
+	c.RefreshToken = parse(c.RefreshToken)
+
+	scopes := make([]string, len(c.Scopes))
+	for i, s := range c.Scopes {
+		scopes[i] = parse(s)
+	}
+	c.Scopes = scopes
+}
+
+// token returns the access token to use for a request to the given url. The
+// cached token is used unless it has expired or renew is set, in which case a
+// new one is obtained. The refresh token is tried first if there is one, with
+// the configured grant being used if that fails.
+func (c OAuth2Config) token(a Auth, u *url.URL, renew bool) (string, error) {
+	st := a.Stash
+	if st == nil {
+		return "", fmt.Errorf(
+			"Could not obtain OAuth2 token: there is no stash to keep it in.")
+	}
+	if sv, err := st.Get(c.stash()); err == nil && !renew &&
+		!sv.Expired(expirySkew) && sv.Value != "" {
+		return sv.Value, nil
+	}
+
+	tokenURL, err := u.Parse(c.TokenURL)
+	if err != nil {
+		return "", fmt.Errorf("Invalid OAuth2 token url: %s", err)
+	}
+
+	if rt, err := st.Get(c.stash() + "Refresh"); err == nil && rt.Value != "" {
+		t, err := c.fetch(a, st, tokenURL, c.form(a, RefreshToken, rt.Value))
+		if err == nil {
+			return t, nil
+		}
+	}
+
+	return c.fetch(a, st, tokenURL, c.form(a, c.grant(), c.RefreshToken))
+}
+
+// form returns the form sent to the token endpoint for the given grant.
+func (c OAuth2Config) form(a Auth, g Grant, refreshToken string) url.Values {
+	f := url.Values{}
+	switch g {
+	case Password:
+		f.Set("grant_type", "password")
+		f.Set("username", a.Username)
+		f.Set("password", a.Password)
+	case RefreshToken:
+		f.Set("grant_type", "refresh_token")
+		f.Set("refresh_token", refreshToken)
+	default:
+		f.Set("grant_type", "client_credentials")
+	}
+	if c.ClientID != "" {
+		f.Set("client_id", c.ClientID)
+	}
+	if c.ClientSecret != "" {
+		f.Set("client_secret", c.ClientSecret)
+	}
+	if len(c.Scopes) > 0 {
+		f.Set("scope", strings.Join(c.Scopes, " "))
+	}
+	return f
+}
+
+// fetch requests a token from the token endpoint, using the token client of the
+// auth, and caches it in the given stash.
+func (c OAuth2Config) fetch(a Auth,
+	st *stash.Map, tokenURL *url.URL, f url.Values) (string, error) {
+	if f.Get("grant_type") == "" {
+		return "", fmt.Errorf("Unsupported OAuth2 grant \"%s\".", c.Grant)
+	}
+
+	req, err := http.NewRequest(
+		http.MethodPost, tokenURL.String(), strings.NewReader(f.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("Could not obtain OAuth2 token: %s", err)
+	}
+	req.Header.Set(headers.ContentType, "application/x-www-form-urlencoded")
+	req.Header.Set(headers.Accept, "application/json")
+
+	client, err := a.tokenClient(tokenURL)
+	if err != nil {
+		return "", fmt.Errorf("Could not obtain OAuth2 token: %s", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("Could not obtain OAuth2 token: %s", err)
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("Could not obtain OAuth2 token: %s", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", fmt.Errorf(
+			"Could not obtain OAuth2 token: %s: %s", resp.Status, b)
+	}
+
+	var tr tokenResponse
+	if err := json.Unmarshal(b, &tr); err != nil {
+		return "", fmt.Errorf("Could not parse OAuth2 token: %s", err)
+	}
+	if tr.AccessToken == "" {
+		return "", fmt.Errorf("Could not obtain OAuth2 token: no access_token sent.")
+	}
+
+	sv := stash.StashValue{
+		Name:   c.stash(),
+		Value:  tr.AccessToken,
+		Origin: tokenURL.String(),
+	}
+	if tr.ExpiresIn > 0 {
+		sv.Expires = time.Now().Add(time.Duration(tr.ExpiresIn) * time.Second)
+	}
+	st.Set(sv.Name, sv)
+
+	if tr.RefreshToken != "" {
+		st.Set(sv.Name+"Refresh", stash.StashValue{
+			Name:   sv.Name + "Refresh",
+			Value:  tr.RefreshToken,
+			Origin: tokenURL.String(),
+		})
+	}
+	return tr.AccessToken, nil
+}
@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hazbo/httpu/stash"
+	"github.com/stretchr/testify/assert"
+)
+
+// tokenServer is a stand-in token endpoint that records the grants it has
+// been sent.
+func tokenServer(grants *[]string, expiresIn int) *httptest.Server {
+	n := 0
+	return httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			*grants = append(*grants, r.PostForm.Get("grant_type"))
+			if r.PostForm.Get("client_id") != "id" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			n++
+			fmt.Fprintf(w,
+				`{"access_token":"token%d","expires_in":%d,"refresh_token":"refresh%d"}`,
+				n, expiresIn, n)
+		}))
+}
+
+func TestOAuth2ClientCredentials(t *testing.T) {
+	var grants []string
+	server := tokenServer(&grants, 3600)
+	defer server.Close()
+
+	a := Auth{Type: OAuth2, Stash: &stash.Map{}, OAuth2: &OAuth2Config{
+		TokenURL: "/oauth/token", ClientID: "id", ClientSecret: "secret"}}
+
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest("GET", server.URL+"/items", nil)
+		assert.Nil(t, a.Apply(req))
+		assert.Equal(t, "Bearer token1", req.Header.Get("Authorization"))
+	}
+
+	// The token should have been cached after the first request.
+	assert.Equal(t, []string{"client_credentials"}, grants)
+	assert.Equal(t, "token1",
+		a.Stash.Parse("${stash["+a.OAuth2.stash()+"]}"))
+
+	// A 401 should cause the token to be refreshed.
+	resp := &http.Response{StatusCode: http.StatusUnauthorized}
+	assert.True(t, a.Challenged(resp))
+
+	req, _ := http.NewRequest("GET", server.URL+"/items", nil)
+	assert.Nil(t, a.Answer(req, resp))
+	assert.Equal(t, "Bearer token2", req.Header.Get("Authorization"))
+	assert.Equal(t, []string{"client_credentials", "refresh_token"}, grants)
+}
+
+func TestOAuth2Expiry(t *testing.T) {
+	var grants []string
+	server := tokenServer(&grants, 1)
+	defer server.Close()
+
+	a := Auth{Type: OAuth2, Username: "bob", Password: "secret",
+		Stash: &stash.Map{}, OAuth2: &OAuth2Config{
+			TokenURL: server.URL, Grant: Password, ClientID: "id", Stash: "tk"}}
+
+	req, _ := http.NewRequest("GET", "http://localhost/", nil)
+	assert.Nil(t, a.Apply(req))
+	assert.Equal(t, "Bearer token1", req.Header.Get("Authorization"))
+
+	// Tokens are refreshed shortly before they expire.
+	sv, _ := a.Stash.Get("tk")
+	assert.True(t, sv.Expired(expirySkew))
+	assert.True(t, sv.Expires.After(time.Now()))
+
+	assert.Nil(t, a.Apply(req))
+	assert.Equal(t, "Bearer token2", req.Header.Get("Authorization"))
+	assert.Equal(t, []string{"password", "refresh_token"}, grants)
+}
+
+func TestOAuth2DefaultStash(t *testing.T) {
+	c := OAuth2Config{TokenURL: "/oauth/token", ClientID: "a"}
+	assert.Regexp(t, "^oauth2Token-[0-9a-f]{8}$", c.stash())
+	assert.Equal(t, c.stash(), c.stash())
+
+	// Tokens obtained in different ways are cached separately.
+	for _, other := range []OAuth2Config{
+		{TokenURL: "/other/token", ClientID: "a"},
+		{TokenURL: "/oauth/token", ClientID: "b"},
+		{TokenURL: "/oauth/token", ClientID: "a", Scopes: []string{"read"}},
+	} {
+		assert.NotEqual(t, c.stash(), other.stash())
+	}
+	assert.Equal(t, "tk", OAuth2Config{Stash: "tk"}.stash())
+}
+
+func TestOAuth2Error(t *testing.T) {
+	var grants []string
+	server := tokenServer(&grants, 0)
+	defer server.Close()
+
+	a := Auth{Type: OAuth2, Stash: &stash.Map{},
+		OAuth2: &OAuth2Config{TokenURL: server.URL}}
+
+	req, _ := http.NewRequest("GET", "http://localhost/", nil)
+	assert.NotNil(t, a.Apply(req))
+
+	// Tokens are never cached outside of the stash of the auth.
+	a = Auth{Type: OAuth2, OAuth2: &OAuth2Config{
+		TokenURL: server.URL, ClientID: "id"}}
+	assert.EqualError(t, a.Apply(req),
+		"Could not obtain OAuth2 token: there is no stash to keep it in.")
+	assert.Equal(t, []string{"client_credentials"}, grants)
+}
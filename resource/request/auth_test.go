@@ -0,0 +1,61 @@
+package request
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/hazbo/httpu/resource/request/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuth(t *testing.T) {
+	r := Request{
+		Scope: &Scope{Defaults: Defaults{
+			Auth: &auth.Auth{Type: auth.Bearer, Token: "project"}}},
+		Spec: RequestSpec{
+			Variants: Variants{
+				Variant{Name: "v1"},
+				Variant{Name: "v2", Auth: &auth.Auth{Type: auth.Bearer, Token: "variant"}},
+			},
+		},
+	}
+
+	assert.Equal(t, "project", r.Auth(nil).Token)
+	assert.Equal(t, "project", r.Auth(&r.Spec.Variants[0]).Token)
+
+	r.Spec.Auth = &auth.Auth{Type: auth.Bearer, Token: "request"}
+	assert.Equal(t, "request", r.Auth(&r.Spec.Variants[0]).Token)
+	assert.Equal(t, "variant", r.Auth(&r.Spec.Variants[1]).Token)
+}
+
+func TestMakeWithAuth(t *testing.T) {
+	teardown := setup()
+	defer teardown()
+
+	os.Setenv("HTTPU_TEST_TOKEN", "abc")
+	defer os.Unsetenv("HTTPU_TEST_TOKEN")
+
+	var got string
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("Authorization")
+	})
+
+	r := Request{
+		Spec: RequestSpec{
+			Uri:     "/",
+			Method:  "GET",
+			Headers: http.Header{},
+			Auth:    &auth.Auth{Type: auth.Bearer, Token: "${env[HTTPU_TEST_TOKEN]}"},
+		},
+	}
+
+	u, _ := url.Parse(server.URL)
+	resp, _, err := r.Make(*u)
+	assert.Nil(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, "Bearer abc", got)
+	assert.Empty(t, r.Spec.Headers.Get("Authorization"))
+}
@@ -0,0 +1,153 @@
+package request
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"strings"
+
+	utils "github.com/hazbo/httpu/utils/common"
+)
+
+// body represents the request body as it will be sent.
+type body struct {
+	reader io.Reader
+	length int64
+	size   *BodySize
+
+	// contentType is set when the content type can only be known once the
+	// body has been created, as is the case with multipart bodies.
+	contentType string
+
+	// streamed is set when the body is read from disk as it is sent, rather
+	// than being held in memory.
+	streamed bool
+}
+
+// body returns the request body that will be sent for the request, compressed
+// if compression has been set. Bodies are wrapped so that progress is reported
+// while they are being sent, if a ProgressFunc has been set.
+func (hr httpRequest) body() (body, error) {
+	var (
+		b   body
+		err error
+	)
+
+	switch {
+	case len(hr.multipart) > 0:
+		b, err = hr.multipart.body(hr.files())
+	case hr.data.Binary:
+		b, err = hr.data.body(hr.files())
+	case hr.graphql != nil:
+		var gb []byte
+		if gb, err = hr.graphql.body(); err == nil {
+			b = body{
+				reader:      bytes.NewReader(gb),
+				length:      int64(len(gb)),
+				contentType: "application/json",
+			}
+		}
+	default:
+		rb := hr.requestBody()
+		b = body{
+			reader: strings.NewReader(rb),
+			length: int64(len(rb)),
+		}
+	}
+	if err != nil {
+		return body{}, err
+	}
+	b.size = &BodySize{encoded: b.length, decoded: b.length}
+
+	// Streamed bodies have their progress reported before being compressed,
+	// as the length of the compressed body is not known up front.
+	if b.streamed {
+		b.withProgress(hr.progress)
+	}
+
+	if hr.compress != "" && b.length > 0 {
+		if b, err = b.compress(hr.compress); err != nil {
+			return body{}, err
+		}
+	}
+
+	if !b.streamed {
+		b.withProgress(hr.progress)
+	}
+	return b, nil
+}
+
+// withProgress wraps the body so that progress is reported as it is read, if
+// a ProgressFunc has been given.
+func (b *body) withProgress(progress ProgressFunc) {
+	if progress == nil || b.length <= 0 {
+		return
+	}
+	b.reader = &progressReader{
+		r:        b.reader,
+		total:    b.length,
+		progress: progress,
+	}
+}
+
+// files returns the file system that files sent as the body are read from,
+// within which no files exist unless the request has a scope of its own.
+func (hr httpRequest) files() fs.FS {
+	if hr.fs != nil {
+		return hr.fs
+	}
+	return noFiles{}
+}
+
+// body opens the file for binary request data so that it can be streamed as
+// the request body. The file is closed once the request has been sent.
+func (rd requestData) body(fsys fs.FS) (body, error) {
+	f, err := fsys.Open(utils.CleanPath(rd.FromFile))
+	if err != nil {
+		return body{}, err
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return body{}, err
+	}
+
+	return body{
+		reader:   f,
+		length:   fi.Size(),
+		streamed: true,
+	}, nil
+}
+
+// ProgressFunc is called with the number of bytes of the request body that
+// have been sent so far, out of the total.
+type ProgressFunc func(sent, total int64)
+
+// progressReader wraps the reader for a request body to report how much of it
+// has been read, and therefore sent.
+type progressReader struct {
+	r        io.Reader
+	sent     int64
+	total    int64
+	progress ProgressFunc
+}
+
+// Read is an implementation of io.Reader.
+func (pr *progressReader) Read(p []byte) (int, error) {
+	n, err := pr.r.Read(p)
+	if n > 0 {
+		pr.sent += int64(n)
+		pr.progress(pr.sent, pr.total)
+	}
+	return n, err
+}
+
+// Close closes the underlying reader if it can be closed, such as when it is
+// the file for binary request data.
+func (pr *progressReader) Close() error {
+	if c, ok := pr.r.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package request
+
+import (
+	"io/ioutil"
+	"testing"
+
+	utils "github.com/hazbo/httpu/utils/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBinaryBody(t *testing.T) {
+	var sent, total int64
+	hr := httpRequest{
+		fs: utils.DirFS("../../"),
+		data: requestData{
+			FromFile: "projects/test_project/data/test.json",
+			Binary:   true,
+		},
+		progress: func(s, t int64) {
+			sent, total = s, t
+		},
+	}
+
+	b, err := hr.body()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(18), b.length)
+
+	c, _ := ioutil.ReadAll(b.reader)
+	assert.Equal(t, `{"error": "false"}`, string(c))
+	assert.Equal(t, int64(18), sent)
+	assert.Equal(t, int64(18), total)
+}
+
+func TestBinaryDataNotParsed(t *testing.T) {
+	rs := RequestSpec{
+		Data: requestData{
+			FromFile: "projects/test_project/data/test.json",
+			Binary:   true,
+		},
+	}
+	rs.UpdateWith(NewScope(utils.DirFS("../../")))
+	assert.Equal(t, "", rs.Data.String())
+}
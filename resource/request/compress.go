@@ -0,0 +1,187 @@
+package request
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/andybalholm/brotli"
+	"github.com/hazbo/httpu/resource/request/headers"
+)
+
+const (
+	encodingGzip    = "gzip"
+	encodingDeflate = "deflate"
+	encodingBrotli  = "br"
+)
+
+// BodySize is the size of a request or response body, both as it was sent
+// and once it has been decoded. These differ when the body is compressed. The
+// size of a response body is only known once it has been read.
+type BodySize struct {
+	encoded int64
+	decoded int64
+}
+
+// Encoded returns the size of the body as it was sent.
+func (bs *BodySize) Encoded() int64 {
+	return atomic.LoadInt64(&bs.encoded)
+}
+
+// Decoded returns the size of the body once it has been decoded.
+func (bs *BodySize) Decoded() int64 {
+	return atomic.LoadInt64(&bs.decoded)
+}
+
+// compressor returns a writer that compresses what is written to it using the
+// given encoding.
+func compressor(w io.Writer, encoding string) (io.WriteCloser, error) {
+	switch encoding {
+	case encodingGzip:
+		return gzip.NewWriter(w), nil
+	case encodingDeflate:
+		return zlib.NewWriter(w), nil
+	case encodingBrotli:
+		return brotli.NewWriter(w), nil
+	}
+	return nil, fmt.Errorf(
+		"Unsupported compression \"%s\", expecting gzip, deflate or br.", encoding)
+}
+
+// compress compresses the request body using the given encoding. Bodies that
+// are already in memory are compressed up front so that their length is known,
+// while streamed bodies are compressed as they are sent.
+func (b body) compress(encoding string) (body, error) {
+	if !b.streamed {
+		var buf bytes.Buffer
+		cw, err := compressor(&buf, encoding)
+		if err != nil {
+			return body{}, err
+		}
+		if _, err := io.Copy(cw, b.reader); err != nil {
+			return body{}, err
+		}
+		if err := cw.Close(); err != nil {
+			return body{}, err
+		}
+
+		b.reader = &buf
+		b.size.encoded = int64(buf.Len())
+		b.length = int64(buf.Len())
+		return b, nil
+	}
+
+	pr, pw := io.Pipe()
+	cw, err := compressor(&countingWriter{w: pw, n: &b.size.encoded}, encoding)
+	if err != nil {
+		return body{}, err
+	}
+	b.size.encoded = 0
+
+	r := b.reader
+	go func() {
+		_, err := io.Copy(cw, r)
+		if err == nil {
+			err = cw.Close()
+		}
+		if c, ok := r.(io.Closer); ok {
+			c.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	b.reader = pr
+	b.length = -1
+	return b, nil
+}
+
+// decodeResponse replaces the body of the response with one that is decoded
+// according to its Content-Encoding. This is needed as the transport only
+// decodes responses itself when it is the one to set Accept-Encoding. The size
+// of the body, before and after decoding, is recorded as it is read.
+func decodeResponse(r *http.Response, size *BodySize) {
+	encoding := strings.ToLower(
+		strings.TrimSpace(r.Header.Get("Content-Encoding")))
+
+	raw := &countingReader{r: r.Body, n: &size.encoded}
+
+	var rd io.Reader = raw
+	switch encoding {
+	case encodingGzip, encodingDeflate, encodingBrotli:
+		rd = &decodingReader{r: raw, encoding: encoding}
+		r.Header.Del("Content-Encoding")
+		r.Header.Del(headers.ContentLength)
+		r.ContentLength = -1
+		r.Uncompressed = true
+	}
+
+	r.Body = &readCloser{
+		Reader: &countingReader{r: rd, n: &size.decoded},
+		Closer: r.Body,
+	}
+}
+
+// decodingReader decodes a response body. The decoder is only created once the
+// body is first read, as responses such as those to HEAD requests can have a
+// Content-Encoding without having a body.
+type decodingReader struct {
+	r        io.Reader
+	encoding string
+	dr       io.Reader
+}
+
+// Read is an implementation of io.Reader.
+func (dr *decodingReader) Read(p []byte) (int, error) {
+	if dr.dr == nil {
+		br := bufio.NewReader(dr.r)
+		h, err := br.Peek(2)
+		if len(h) == 0 {
+			return 0, err
+		}
+
+		switch dr.encoding {
+		case encodingGzip:
+			dr.dr, err = gzip.NewReader(br)
+		case encodingDeflate:
+			// Deflate should be sent with a zlib header, however some servers
+			// send raw deflate data instead.
+			if len(h) == 2 && h[0]&0x0f == 8 && (uint16(h[0])<<8|uint16(h[1]))%31 == 0 {
+				dr.dr, err = zlib.NewReader(br)
+			} else {
+				dr.dr = flate.NewReader(br)
+			}
+		case encodingBrotli:
+			dr.dr = brotli.NewReader(br)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("Could not decode %s response: %s", dr.encoding, err)
+		}
+	}
+	return dr.dr.Read(p)
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n *int64
+}
+
+// Read is an implementation of io.Reader.
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	atomic.AddInt64(cr.n, int64(n))
+	return n, err
+}
+
+// readCloser joins a reader with a separate closer.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
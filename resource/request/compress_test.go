@@ -0,0 +1,96 @@
+package request
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompressRequest(t *testing.T) {
+	var received string
+	s := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+			gr, err := gzip.NewReader(r.Body)
+			assert.Nil(t, err)
+			b, _ := ioutil.ReadAll(gr)
+			received = string(b)
+		}))
+	defer s.Close()
+
+	hr := httpRequest{
+		url:      s.URL,
+		method:   "POST",
+		headers:  http.Header{},
+		data:     requestData{contents: []byte(fixture())},
+		compress: "gzip",
+	}
+
+	_, stat, err := hr.make()
+	assert.Nil(t, err)
+	assert.Equal(t, fixture(), received)
+	assert.Equal(t, int64(len(fixture())), stat.Request.Decoded())
+	assert.NotEqual(t, stat.Request.Decoded(), stat.Request.Encoded())
+}
+
+func TestCompressUnsupported(t *testing.T) {
+	hr := httpRequest{
+		method:   "POST",
+		data:     requestData{contents: []byte(fixture())},
+		compress: "zip",
+	}
+	_, err := hr.body()
+	assert.NotNil(t, err)
+}
+
+func TestDecodeResponse(t *testing.T) {
+	encoders := map[string]func(*bytes.Buffer) ([]byte, error){
+		"br": func(b *bytes.Buffer) ([]byte, error) {
+			w := brotli.NewWriter(b)
+			w.Write([]byte(fixture()))
+			return b.Bytes(), w.Close()
+		},
+		"deflate": func(b *bytes.Buffer) ([]byte, error) {
+			w, _ := flate.NewWriter(b, flate.DefaultCompression)
+			w.Write([]byte(fixture()))
+			return b.Bytes(), w.Close()
+		},
+	}
+
+	for enc, encode := range encoders {
+		var b bytes.Buffer
+		if _, err := encode(&b); err != nil {
+			t.Fatal(err)
+		}
+
+		s := httptest.NewServer(http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, enc, r.Header.Get("Accept-Encoding"))
+				w.Header().Set("Content-Encoding", enc)
+				w.Write(b.Bytes())
+			}))
+
+		hr := httpRequest{
+			url:     s.URL,
+			method:  "GET",
+			headers: http.Header{"Accept-Encoding": {enc}},
+		}
+
+		resp, stat, err := hr.make()
+		assert.Nil(t, err)
+
+		body, err := ioutil.ReadAll(resp.Body)
+		assert.Nil(t, err)
+		assert.Equal(t, fixture(), string(body), enc)
+		assert.Equal(t, int64(b.Len()), stat.Response.Encoded(), enc)
+		assert.Equal(t, int64(len(fixture())), stat.Response.Decoded(), enc)
+		s.Close()
+	}
+}
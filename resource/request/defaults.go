@@ -0,0 +1,16 @@
+package request
+
+import (
+	"github.com/hazbo/httpu/resource/request/auth"
+	"github.com/hazbo/httpu/resource/request/signer"
+)
+
+// Defaults represents the settings made at the project level that apply to
+// every request, unless they are overridden by the request or its variant.
+type Defaults struct {
+	Auth      *auth.Auth
+	Sign      *signer.Config
+	Protocol  string
+	Transport *Transport
+	Proxy     *Proxy
+}
@@ -0,0 +1,182 @@
+package request
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hazbo/httpu/schema"
+)
+
+// inherited returns the fields of the request spec that are inherited by each
+// of its variants. Headers, query and path parameters, auth and the rest are
+// left out, as they are already merged with those of the variant as it is
+// made.
+func (rs RequestSpec) inherited() Variant {
+	return Variant{
+		Method:      rs.Method,
+		Data:        rs.Data,
+		FormData:    rs.FormData,
+		Multipart:   rs.Multipart,
+		StashValues: rs.StashValues,
+		Assertions:  rs.Assertions,
+	}
+}
+
+// variant returns the request spec as a variant, so that it can be extended by
+// the variants of other requests. The uri is left out, as the path of a variant
+// is always added to the uri of its own request.
+func (rs RequestSpec) variant() Variant {
+	v := rs.inherited()
+	v.Query = rs.Query
+	v.PathParams = rs.PathParams
+	v.Headers = rs.Headers
+	v.Auth = rs.Auth
+	v.Sign = rs.Sign
+	v.GraphQL = rs.GraphQL
+	v.GRPC = rs.GRPC
+	return v
+}
+
+// ExtendsError is an error with what a variant extends, such as it not
+// existing.
+type ExtendsError struct {
+	Request string
+	Variant string
+
+	// Index is the index of the variant within its request.
+	Index int
+
+	Message string
+	Hint    string
+}
+
+// Error returns the error along with the variant that it is for.
+func (e ExtendsError) Error() string {
+	return fmt.Sprintf("Could not extend variant \"%s\" of \"%s\": %s",
+		e.Variant, e.Request, e.Message)
+}
+
+// ResolveExtends replaces each variant of the given requests with one that
+// inherits from what it extends, if anything, followed by its own request, in
+// place. An error is returned for each variant that extends something that does
+// not exist, or that ends up extending itself, with those variants inheriting
+// from their own request alone.
+func ResolveExtends(rm map[string]Request) []ExtendsError {
+	er := extendsResolver{
+		requests:  rm,
+		resolved:  map[string]bool{},
+		resolving: map[string]bool{},
+	}
+
+	// Requests are resolved in order of their names, so that the same errors
+	// are always given for variants that extend one another.
+	for _, name := range er.names() {
+		for i := range rm[name].Spec.Variants {
+			er.resolve(name, i)
+		}
+	}
+	return er.errs
+}
+
+// extendsResolver resolves variants in turn, resolving what each one extends
+// before it, so that variants can build on one another.
+type extendsResolver struct {
+	requests  map[string]Request
+	resolved  map[string]bool
+	resolving map[string]bool
+	errs      []ExtendsError
+}
+
+// names returns the sorted names of the requests.
+func (er *extendsResolver) names() []string {
+	names := make([]string, 0, len(er.requests))
+	for n := range er.requests {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resolve resolves the variant at the given index of the named request,
+// returning it, or false if it is already being resolved, such that something
+// it extends extends it.
+func (er *extendsResolver) resolve(name string, i int) (Variant, bool) {
+	r := er.requests[name]
+	v := r.Spec.Variants[i]
+	key := fmt.Sprintf("%s.%d", name, i)
+	if er.resolved[key] {
+		return v, true
+	}
+	if er.resolving[key] {
+		return v, false
+	}
+
+	if v.Extends != "" {
+		er.resolving[key] = true
+		p, err := er.parent(r, v.Extends)
+		delete(er.resolving, key)
+		if err != nil {
+			err.Request, err.Variant, err.Index = name, v.Name, i
+			er.errs = append(er.errs, *err)
+		} else {
+			v = v.Inherit(p)
+		}
+	}
+
+	// The variants share their backing array with the request held within
+	// the map, so the variant is replaced there too.
+	v = v.Inherit(r.Spec.inherited())
+	r.Spec.Variants[i] = v
+	er.resolved[key] = true
+	return v, true
+}
+
+// parent returns what a variant of the given request extends, which is looked
+// for first within the variants of the request, then within other requests.
+func (er *extendsResolver) parent(
+	r Request, extends string) (Variant, *ExtendsError) {
+	for i, v := range r.Spec.Variants {
+		if v.Name == extends {
+			return er.resolveParent(r.Name, i, extends)
+		}
+	}
+
+	name, variant, ok := strings.Cut(extends, ".")
+	o, exists := er.requests[name]
+	if !exists {
+		names := append(r.Variants().Names(), er.names()...)
+		return Variant{}, &ExtendsError{
+			Message: fmt.Sprintf("unknown variant or request \"%s\"", extends),
+			Hint:    schema.Suggest(extends, names),
+		}
+	}
+	if !ok {
+		return o.Spec.variant(), nil
+	}
+
+	for i, v := range o.Spec.Variants {
+		if v.Name == variant {
+			return er.resolveParent(name, i, extends)
+		}
+	}
+	return Variant{}, &ExtendsError{
+		Message: fmt.Sprintf(
+			"unknown variant \"%s\" of request \"%s\"", variant, name),
+		Hint: schema.Suggest(variant, o.Variants().Names()),
+	}
+}
+
+// resolveParent resolves the variant that is being extended, returning an error
+// if it ends up extending the variant that extends it.
+func (er *extendsResolver) resolveParent(
+	name string, i int, extends string) (Variant, *ExtendsError) {
+	p, ok := er.resolve(name, i)
+	if !ok {
+		return Variant{}, &ExtendsError{
+			Message: fmt.Sprintf("circular extends \"%s\"", extends),
+			Hint:    "variants cannot extend themselves",
+		}
+	}
+	return p, nil
+}
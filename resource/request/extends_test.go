@@ -0,0 +1,95 @@
+package request
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInherit(t *testing.T) {
+	p := Variant{
+		Path:     "/users",
+		Method:   "POST",
+		Data:     requestData{contents: []byte(`{}`)},
+		Query:    url.Values{"page": {"1"}, "limit": {"10"}},
+		Headers:  http.Header{"Accept": {"application/json"}},
+		FormData: url.Values{},
+	}
+
+	v := Variant{
+		Query:   url.Values{"page": {"2"}},
+		Headers: http.Header{"X-Test": {"yes"}},
+	}.Inherit(p)
+	assert.Equal(t, "/users", v.Path)
+	assert.Equal(t, "POST", v.Method)
+	assert.Equal(t, "{}", v.Data.String())
+	assert.Equal(t, url.Values{"page": {"2"}, "limit": {"10"}}, v.Query)
+	assert.Equal(t, http.Header{
+		"Accept": {"application/json"}, "X-Test": {"yes"}}, v.Headers)
+
+	// The body is inherited as a whole.
+	v = Variant{FormData: url.Values{"name": {"test"}}}.Inherit(p)
+	assert.Equal(t, "", v.Data.String())
+	assert.Equal(t, url.Values{"name": {"test"}}, v.FormData)
+
+	// Variants with another method do not inherit the body.
+	v = Variant{Method: "GET"}.Inherit(p)
+	assert.Equal(t, "GET", v.Method)
+	assert.True(t, v.Data.empty())
+	assert.Empty(t, v.FormData)
+	v = Variant{Method: "post"}.Inherit(p)
+	assert.Equal(t, "{}", v.Data.String())
+}
+
+func TestResolveExtends(t *testing.T) {
+	rm := map[string]Request{
+		"users": {Name: "users", Spec: RequestSpec{
+			Uri:     "/users",
+			Method:  "GET",
+			Headers: http.Header{"Accept": {"application/json"}},
+			Variants: Variants{
+				{Name: "create", Method: "POST", Path: "/new"},
+				{Name: "create-admin", Extends: "create",
+					Query: url.Values{"admin": {"true"}}},
+			},
+		}},
+		"orders": {Name: "orders", Spec: RequestSpec{
+			Uri: "/orders",
+			Variants: Variants{
+				{Name: "as-users", Extends: "users"},
+				{Name: "admin", Extends: "users.create-admin"},
+				{Name: "missing", Extends: "usres"},
+				{Name: "missing-variant", Extends: "users.craete"},
+				{Name: "a", Extends: "b"},
+				{Name: "b", Extends: "a"},
+			},
+		}},
+	}
+
+	errs := ResolveExtends(rm)
+
+	vs := rm["users"].Spec.Variants
+	assert.Equal(t, "POST", vs[1].Method)
+	assert.Equal(t, "/new", vs[1].Path)
+
+	vs = rm["orders"].Spec.Variants
+	assert.Equal(t, "GET", vs[0].Method)
+	assert.Equal(t, "", vs[0].Path, "the uri of a request is not inherited")
+	assert.Equal(t, http.Header{"Accept": {"application/json"}}, vs[0].Headers)
+	assert.Equal(t, "POST", vs[1].Method)
+	assert.Equal(t, url.Values{"admin": {"true"}}, vs[1].Query)
+
+	assert.Equal(t, []ExtendsError{
+		{Request: "orders", Variant: "missing", Index: 2,
+			Message: `unknown variant or request "usres"`,
+			Hint:    `did you mean "users"?`},
+		{Request: "orders", Variant: "missing-variant", Index: 3,
+			Message: `unknown variant "craete" of request "users"`,
+			Hint:    `did you mean "create"?`},
+		{Request: "orders", Variant: "b", Index: 5,
+			Message: `circular extends "a"`,
+			Hint:    "variants cannot extend themselves"},
+	}, errs)
+}
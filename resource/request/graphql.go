@@ -0,0 +1,121 @@
+package request
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// GraphQL represents the query sent by a GraphQL request, which is loaded from
+// a .graphql file. Variables can contain env, stash and prompt variables in the
+// same way as request data. Variants can set their own query, variables or
+// operation name, replacing those of the request.
+type GraphQL struct {
+	Query         requestData     `json:"query"`
+	Variables     json.RawMessage `json:"variables"`
+	OperationName string          `json:"operationName"`
+}
+
+// graphQLBody is the body sent for a GraphQL request.
+type graphQLBody struct {
+	Query         string          `json:"query"`
+	Variables     json.RawMessage `json:"variables,omitempty"`
+	OperationName string          `json:"operationName,omitempty"`
+}
+
+// body returns the JSON encoded body to be sent for the query.
+func (g GraphQL) body() ([]byte, error) {
+	if len(g.Variables) > 0 && !json.Valid(g.Variables) {
+		return nil, fmt.Errorf("GraphQL variables are not valid JSON.")
+	}
+	return json.Marshal(graphQLBody{
+		Query:         g.Query.String(),
+		Variables:     g.Variables,
+		OperationName: g.OperationName,
+	})
+}
+
+// clone returns a copy of the query.
+func (g *GraphQL) clone() *GraphQL {
+	if g == nil {
+		return nil
+	}
+	c := *g
+	c.Variables = append(json.RawMessage(nil), g.Variables...)
+	return &c
+}
+
+// strings returns each string within the query that may contain variables.
+func (g GraphQL) strings() []string {
+	return []string{string(g.Variables), g.OperationName}
+}
+
+// parse replaces any variables within the query variables and operation name.
+// The query itself is left as it is, as GraphQL variables should be used
+// instead.
+func (g *GraphQL) parse(parse parser) {
+	if len(g.Variables) > 0 {
+		g.Variables = json.RawMessage(parse(string(g.Variables)))
+	}
+	g.OperationName = parse(g.OperationName)
+}
+
+// setVariable sets a single variable, in the same way that fields are set
+// within the request data.
+func (g *GraphQL) setVariable(name, value string) error {
+	b, err := setJSONField(g.Variables, name, value)
+	if err != nil {
+		return fmt.Errorf("Could not set GraphQL variable \"%s\": %s", name, err)
+	}
+	g.Variables = b
+	return nil
+}
+
+// mergeGraphQL returns the query to be sent for a variant, using what has been
+// set by the variant over what has been set by the request.
+func mergeGraphQL(rg, vg *GraphQL) *GraphQL {
+	if rg == nil {
+		return vg.clone()
+	}
+	g := rg.clone()
+	if vg == nil {
+		return g
+	}
+	if vg.Query.FromFile != "" || vg.Query.String() != "" {
+		g.Query = vg.Query
+	}
+	if len(vg.Variables) > 0 {
+		g.Variables = append(json.RawMessage(nil), vg.Variables...)
+	}
+	if vg.OperationName != "" {
+		g.OperationName = vg.OperationName
+	}
+	return g
+}
+
+// GraphQL returns the query sent by the request, and the given variant if
+// there is one. Nil is returned if the request is not a GraphQL request.
+func (r Request) GraphQL(v *Variant) *GraphQL {
+	if v == nil {
+		return r.Spec.GraphQL.clone()
+	}
+	return mergeGraphQL(r.Spec.GraphQL, v.GraphQL)
+}
+
+// String returns the query that will be sent.
+func (g GraphQL) String() string {
+	return g.Query.String()
+}
+
+// WithQuery returns a copy of the request that sends the given query in place
+// of its own, with no variables or operation name. This is used to send
+// queries such as the introspection query to the same endpoint, with the same
+// headers and auth, as the request.
+func (r Request) WithQuery(query string) Request {
+	c := r.Clone()
+	c.Spec.Variants = nil
+	c.Spec.GraphQL = &GraphQL{Query: requestData{contents: []byte(query)}}
+	if c.Spec.Method == "" {
+		c.Spec.Method = "POST"
+	}
+	return c
+}
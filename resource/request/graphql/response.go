@@ -0,0 +1,52 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Location is the line and column within the query that an error relates to.
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Error represents a single error returned by a GraphQL endpoint.
+type Error struct {
+	Message   string        `json:"message"`
+	Path      []interface{} `json:"path"`
+	Locations []Location    `json:"locations"`
+}
+
+// String returns the error message, along with where the error occurred.
+func (e Error) String() string {
+	s := e.Message
+	if len(e.Path) > 0 {
+		p := make([]string, len(e.Path))
+		for i, pe := range e.Path {
+			p[i] = fmt.Sprintf("%v", pe)
+		}
+		s += fmt.Sprintf(" (path: %s)", strings.Join(p, "."))
+	}
+	for _, l := range e.Locations {
+		s += fmt.Sprintf(" (line %d, column %d)", l.Line, l.Column)
+	}
+	return s
+}
+
+// Response represents the response to a GraphQL request, where data and
+// errors can both be sent at once.
+type Response struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []Error         `json:"errors"`
+}
+
+// ParseResponse parses the body of a response to a GraphQL request.
+func ParseResponse(b []byte) (Response, error) {
+	var r Response
+	if err := json.Unmarshal(b, &r); err != nil {
+		return Response{}, fmt.Errorf("Could not parse GraphQL response: %s", err)
+	}
+	return r, nil
+}
@@ -0,0 +1,199 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// IntrospectionQuery is sent to fetch the schema of a GraphQL endpoint. Only
+// what is needed to list and complete types and fields is asked for.
+const IntrospectionQuery = `query IntrospectionQuery {
+  __schema {
+    queryType { name }
+    mutationType { name }
+    subscriptionType { name }
+    types {
+      kind
+      name
+      description
+      fields(includeDeprecated: true) {
+        name
+        description
+        args { name type { ...TypeRef } }
+        type { ...TypeRef }
+      }
+      inputFields { name description type { ...TypeRef } }
+      enumValues(includeDeprecated: true) { name }
+    }
+  }
+}
+
+fragment TypeRef on __Type {
+  kind
+  name
+  ofType { kind name ofType { kind name ofType { kind name ofType { kind name } } } }
+}`
+
+// TypeRef is a reference to a type, which can be wrapped as a list or as
+// non-null.
+type TypeRef struct {
+	Kind   string   `json:"kind"`
+	Name   string   `json:"name"`
+	OfType *TypeRef `json:"ofType"`
+}
+
+// String returns the type as it would be written in a GraphQL schema, such as
+// [Product!]!.
+func (tr TypeRef) String() string {
+	switch {
+	case tr.Kind == "NON_NULL" && tr.OfType != nil:
+		return tr.OfType.String() + "!"
+	case tr.Kind == "LIST" && tr.OfType != nil:
+		return "[" + tr.OfType.String() + "]"
+	}
+	return tr.Name
+}
+
+// Field represents a field of a type, or an argument or input field.
+type Field struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Args        []Field `json:"args"`
+	Type        TypeRef `json:"type"`
+}
+
+// String returns the field along with its arguments and type.
+func (f Field) String() string {
+	if len(f.Args) == 0 {
+		return fmt.Sprintf("%s: %s", f.Name, f.Type)
+	}
+	args := make([]string, len(f.Args))
+	for i, a := range f.Args {
+		args[i] = fmt.Sprintf("%s: %s", a.Name, a.Type)
+	}
+	return fmt.Sprintf("%s(%s): %s", f.Name, strings.Join(args, ", "), f.Type)
+}
+
+// Type represents a named type within the schema.
+type Type struct {
+	Kind        string  `json:"kind"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Fields      []Field `json:"fields"`
+	InputFields []Field `json:"inputFields"`
+	EnumValues  []struct {
+		Name string `json:"name"`
+	} `json:"enumValues"`
+}
+
+// Members returns the names of the fields, input fields or enum values of the
+// type, depending on its kind.
+func (t Type) Members() []string {
+	var ms []string
+	for _, f := range t.Fields {
+		ms = append(ms, f.Name)
+	}
+	for _, f := range t.InputFields {
+		ms = append(ms, f.Name)
+	}
+	for _, e := range t.EnumValues {
+		ms = append(ms, e.Name)
+	}
+	return ms
+}
+
+// Field returns the field or input field of the type with the given name.
+func (t Type) Field(name string) (Field, bool) {
+	for _, f := range append(t.Fields, t.InputFields...) {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
+
+// Schema represents the schema of a GraphQL endpoint, as returned by the
+// introspection query.
+type Schema struct {
+	QueryType        *struct{ Name string } `json:"queryType"`
+	MutationType     *struct{ Name string } `json:"mutationType"`
+	SubscriptionType *struct{ Name string } `json:"subscriptionType"`
+	Types            []Type                 `json:"types"`
+}
+
+// ParseSchema parses the response to the introspection query. Any errors
+// returned by the server are returned as an error.
+func ParseSchema(b []byte) (Schema, error) {
+	r, err := ParseResponse(b)
+	if err != nil {
+		return Schema{}, err
+	}
+	if len(r.Errors) > 0 {
+		return Schema{}, fmt.Errorf("Could not fetch schema: %s", r.Errors[0])
+	}
+
+	var data struct {
+		Schema *Schema `json:"__schema"`
+	}
+	if err := json.Unmarshal(r.Data, &data); err != nil {
+		return Schema{}, fmt.Errorf("Could not parse schema: %s", err)
+	}
+	if data.Schema == nil {
+		return Schema{}, fmt.Errorf("Could not parse schema: no __schema sent.")
+	}
+
+	sort.Slice(data.Schema.Types, func(i, j int) bool {
+		return data.Schema.Types[i].Name < data.Schema.Types[j].Name
+	})
+	return *data.Schema, nil
+}
+
+// Type returns the type with the given name.
+func (s Schema) Type(name string) (Type, bool) {
+	for _, t := range s.Types {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Type{}, false
+}
+
+// Names returns the name of each type, leaving out the built in types used for
+// introspection.
+func (s Schema) Names() []string {
+	var ns []string
+	for _, t := range s.Types {
+		if !strings.HasPrefix(t.Name, "__") {
+			ns = append(ns, t.Name)
+		}
+	}
+	return ns
+}
+
+// Complete returns each type or field name that begins with the given prefix.
+// Fields are completed once the prefix contains the name of a type followed by
+// a dot, such as Product.na.
+func (s Schema) Complete(prefix string) []string {
+	var res []string
+	if i := strings.IndexByte(prefix, '.'); i >= 0 {
+		t, ok := s.Type(prefix[:i])
+		if !ok {
+			return nil
+		}
+		for _, m := range t.Members() {
+			if strings.HasPrefix(m, prefix[i+1:]) {
+				res = append(res, t.Name+"."+m)
+			}
+		}
+		return res
+	}
+
+	for _, n := range s.Names() {
+		if strings.HasPrefix(n, prefix) {
+			res = append(res, n)
+		}
+	}
+	return res
+}
@@ -0,0 +1,60 @@
+package graphql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const introspection = `{"data": {"__schema": {
+  "queryType": {"name": "Query"},
+  "types": [
+    {"kind": "OBJECT", "name": "Query", "fields": [
+      {"name": "product", "args": [
+        {"name": "id", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "ID"}}}
+      ], "type": {"kind": "OBJECT", "name": "Product"}},
+      {"name": "products", "args": [], "type": {"kind": "NON_NULL", "ofType":
+        {"kind": "LIST", "ofType": {"kind": "OBJECT", "name": "Product"}}}}
+    ]},
+    {"kind": "OBJECT", "name": "Product", "fields": [
+      {"name": "id", "args": [], "type": {"kind": "SCALAR", "name": "ID"}},
+      {"name": "name", "args": [], "type": {"kind": "SCALAR", "name": "String"}}
+    ]},
+    {"kind": "ENUM", "name": "ProductStatus", "enumValues": [{"name": "LIVE"}]},
+    {"kind": "OBJECT", "name": "__Type", "fields": []}
+  ]
+}}}`
+
+func TestParseSchema(t *testing.T) {
+	s, err := ParseSchema([]byte(introspection))
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{"Product", "ProductStatus", "Query"}, s.Names())
+
+	q, ok := s.Type("Query")
+	assert.True(t, ok)
+	assert.Equal(t, "product(id: ID!): Product", q.Fields[0].String())
+	assert.Equal(t, "products: [Product]!", q.Fields[1].String())
+
+	_, err = ParseSchema([]byte(`{"errors": [{"message": "introspection disabled"}]}`))
+	assert.NotNil(t, err)
+}
+
+func TestComplete(t *testing.T) {
+	s, _ := ParseSchema([]byte(introspection))
+
+	assert.Equal(t, []string{"Product", "ProductStatus"}, s.Complete("Pro"))
+	assert.Equal(t, []string{"Product.name"}, s.Complete("Product.n"))
+	assert.Equal(t, []string{"ProductStatus.LIVE"}, s.Complete("ProductStatus."))
+	assert.Empty(t, s.Complete("Nothing.i"))
+}
+
+func TestParseResponse(t *testing.T) {
+	r, err := ParseResponse([]byte(`{"data": {"product": null}, "errors": [
+	  {"message": "Not found", "path": ["product", 0], "locations": [{"line": 1, "column": 3}]}
+	]}`))
+	assert.Nil(t, err)
+
+	assert.JSONEq(t, `{"product": null}`, string(r.Data))
+	assert.Equal(t, "Not found (path: product.0) (line 1, column 3)", r.Errors[0].String())
+}
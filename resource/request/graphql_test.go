@@ -0,0 +1,86 @@
+package request
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMakeGraphQL(t *testing.T) {
+	teardown := setup()
+	defer teardown()
+
+	os.Setenv("HTTPU_TEST_FIRST", "10")
+	defer os.Unsetenv("HTTPU_TEST_FIRST")
+
+	var (
+		got         graphQLBody
+		contentType string
+	)
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		b, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(b, &got)
+	})
+
+	r := Request{
+		Kind: KindGraphQL,
+		Spec: RequestSpec{
+			Uri:     "/graphql",
+			Method:  "POST",
+			Headers: http.Header{},
+			GraphQL: &GraphQL{
+				Query:     requestData{contents: []byte("query Products { products { id } }")},
+				Variables: json.RawMessage(`{"first": ${env[HTTPU_TEST_FIRST]}}`),
+			},
+			Variants: Variants{
+				Variant{
+					Name:   "byId",
+					Method: "POST",
+					GraphQL: &GraphQL{
+						Query:         requestData{contents: []byte("query Product { product { id } }")},
+						OperationName: "Product",
+					},
+				},
+			},
+		},
+	}
+
+	u, _ := url.Parse(server.URL)
+
+	c := r.Clone()
+	resp, _, err := c.Make(*u)
+	assert.Nil(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, "application/json", contentType)
+	assert.Equal(t, "query Products { products { id } }", got.Query)
+	assert.JSONEq(t, `{"first": 10}`, string(got.Variables))
+	assert.Empty(t, got.OperationName)
+
+	// Variants replace the query, but keep the variables of the request
+	// unless they set their own, which can also be set as arguments.
+	c = r.Clone()
+	v := &c.Spec.Variants[0]
+	a, _ := ParseArgs([]string{"id=5"})
+	assert.Nil(t, a.Apply(&c, v))
+
+	resp, _, err = c.MakeWithVariant(*u, v)
+	assert.Nil(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, "query Product { product { id } }", got.Query)
+	assert.JSONEq(t, `{"first": 10, "id": 5}`, string(got.Variables))
+	assert.Equal(t, "Product", got.OperationName)
+}
+
+func TestGraphQLInvalidVariables(t *testing.T) {
+	g := GraphQL{Variables: json.RawMessage(`{"first": }`)}
+	_, err := g.body()
+	assert.NotNil(t, err)
+}
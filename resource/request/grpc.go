@@ -0,0 +1,221 @@
+package request
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/hazbo/httpu/resource/request/grpc"
+	"github.com/hazbo/httpu/resource/request/headers"
+	utils "github.com/hazbo/httpu/utils/common"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// GRPC represents the method called by a gRPC request, along with the message
+// sent to it as JSON. The headers of the request are sent as metadata.
+//
+// Descriptors are compiled from ProtoFiles when they are given, which are
+// looked for within each of ImportPaths, or within the project itself if there
+// are none. Otherwise they are fetched from the server using server
+// reflection. Variants can call another method, or send their own message.
+type GRPC struct {
+	Service     string          `json:"service"`
+	Method      string          `json:"method"`
+	Message     json.RawMessage `json:"message"`
+	ProtoFiles  []string        `json:"protoFiles"`
+	ImportPaths []string        `json:"importPaths"`
+}
+
+// Path returns the path at which the method is called.
+func (g GRPC) Path() string {
+	return fmt.Sprintf("/%s/%s", g.Service, g.Method)
+}
+
+// clone returns a copy of the method.
+func (g *GRPC) clone() *GRPC {
+	if g == nil {
+		return nil
+	}
+	c := *g
+	c.Message = append(json.RawMessage(nil), g.Message...)
+	c.ProtoFiles = append([]string(nil), g.ProtoFiles...)
+	c.ImportPaths = append([]string(nil), g.ImportPaths...)
+	return &c
+}
+
+// strings returns each string within the method that may contain variables.
+func (g GRPC) strings() []string {
+	return []string{g.Service, g.Method, string(g.Message)}
+}
+
+// parse replaces any variables within the method and its message.
+func (g *GRPC) parse(parse parser) {
+	g.Service = parse(g.Service)
+	g.Method = parse(g.Method)
+	if len(g.Message) > 0 {
+		g.Message = json.RawMessage(parse(string(g.Message)))
+	}
+}
+
+// setField sets a field within the message, in the same way that fields are set
+// within the request data.
+func (g *GRPC) setField(name, value string) error {
+	b, err := setJSONField(g.Message, name, value)
+	if err != nil {
+		return fmt.Errorf("Could not set field \"%s\" in message: %s", name, err)
+	}
+	g.Message = b
+	return nil
+}
+
+// mergeGRPC returns the method called for a variant, using the service, method
+// and message set by the variant over those set by the request.
+func mergeGRPC(rg *GRPC, v *Variant) *GRPC {
+	g := rg.clone()
+	if g == nil {
+		g = v.GRPC.clone()
+	} else if vg := v.GRPC; vg != nil {
+		if vg.Service != "" {
+			g.Service = vg.Service
+		}
+		if vg.Method != "" {
+			g.Method = vg.Method
+		}
+		if len(vg.Message) > 0 {
+			g.Message = append(json.RawMessage(nil), vg.Message...)
+		}
+	}
+	if g != nil && len(v.Message) > 0 {
+		g.Message = append(json.RawMessage(nil), v.Message...)
+	}
+	return g
+}
+
+// GRPC returns the method called by the request, and the given variant if
+// there is one. Nil is returned if the request is not a gRPC request.
+func (r Request) GRPC(v *Variant) *GRPC {
+	if v == nil {
+		return r.Spec.GRPC.clone()
+	}
+	return mergeGRPC(r.Spec.GRPC, v)
+}
+
+// descriptors returns the descriptors used to encode and decode the messages
+// of the method, either compiled from its proto files or fetched from the
+// server.
+func (g GRPC) descriptors(fsys fs.FS,
+	client *http.Client, newRequest grpc.RequestFunc) (grpc.Descriptors, error) {
+	if len(g.ProtoFiles) == 0 {
+		return grpc.Reflect(client, newRequest, g.Service)
+	}
+
+	ips := []string{"."}
+	if len(g.ImportPaths) > 0 {
+		ips = nil
+		for _, ip := range g.ImportPaths {
+			ips = append(ips, utils.CleanPath(ip))
+		}
+	}
+	return grpc.Compile(fsys, g.ProtoFiles, ips)
+}
+
+// grpcRequest returns the request that calls the gRPC method, with the message
+// encoded as its body, along with the descriptor of the method used to decode
+// the response.
+func (hr httpRequest) grpcRequest(
+	client *http.Client) (httpRequest, protoreflect.MethodDescriptor, error) {
+	u, err := url.Parse(hr.url)
+	if err != nil {
+		return httpRequest{}, nil, err
+	}
+	base := strings.TrimSuffix(u.Path, hr.grpc.Path())
+
+	hr.method = http.MethodPost
+	hr.headers = grpcHeaders(hr.headers)
+	hr.formData, hr.multipart = nil, nil
+	hr.compress = ""
+	hr.graphql = nil
+
+	// Descriptors fetched using reflection are fetched with the same headers
+	// and auth as the call itself.
+	newRequest := func(path string, b []byte) (*http.Request, error) {
+		c := hr
+		ru := *u
+		ru.Path = base + path
+		c.url = ru.String()
+		c.data = requestData{contents: b}
+		c.progress = nil
+		req, _, err := c.newRequest()
+		return req, err
+	}
+
+	d, err := hr.grpc.descriptors(hr.files(), client, newRequest)
+	if err != nil {
+		return httpRequest{}, nil, err
+	}
+	md, err := grpc.FindMethod(d, hr.grpc.Service, hr.grpc.Method)
+	if err != nil {
+		return httpRequest{}, nil, err
+	}
+	msgs, err := grpc.Marshal(md, hr.grpc.Message)
+	if err != nil {
+		return httpRequest{}, nil, err
+	}
+	hr.data = requestData{contents: grpc.Frame(msgs...)}
+	return hr, md, nil
+}
+
+// grpcHeaders returns a copy of the headers with those needed to make a gRPC
+// call set.
+func grpcHeaders(h http.Header) http.Header {
+	c := cloneHeader(h)
+	if c == nil {
+		c = http.Header{}
+	}
+	c.Set(headers.ContentType, grpc.ContentType)
+	c.Set("TE", "trailers")
+	return c
+}
+
+// decodeGRPCResponse replaces the body of a gRPC response with the response
+// messages decoded as JSON. If the call failed, the body is replaced with the
+// status of the call instead, with the status code of the response being set to
+// the closest HTTP status. Responses that were not sent by a gRPC server, such
+// as an error from a proxy in front of it, are left as they are.
+func decodeGRPCResponse(
+	resp *http.Response, md protoreflect.MethodDescriptor) error {
+	if !grpc.IsResponse(resp) {
+		return nil
+	}
+
+	msgs, err := grpc.ReadFrames(resp.Body, resp.Header.Get("Grpc-Encoding"))
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+
+	s, ok := grpc.StatusFromResponse(resp)
+	if !ok {
+		s = grpc.Status{
+			Code: grpc.Unknown, Message: "Server did not send a grpc-status."}
+	}
+
+	var b []byte
+	if s.Code != grpc.OK {
+		b, _ = json.Marshal(s)
+		resp.StatusCode = s.Code.HTTPStatus()
+		resp.Status = fmt.Sprintf("%d %s", resp.StatusCode, s.Code)
+	} else if b, err = grpc.Unmarshal(md, msgs); err != nil {
+		return err
+	}
+
+	resp.Header.Set(headers.ContentType, "application/json")
+	resp.ContentLength = int64(len(b))
+	resp.Body = ioutil.NopCloser(bytes.NewReader(b))
+	return nil
+}
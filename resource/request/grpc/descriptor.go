@@ -0,0 +1,139 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// Descriptors finds descriptors by their fully qualified name, such as
+// "users.v1.Users".
+type Descriptors interface {
+	FindDescriptorByName(protoreflect.FullName) (protoreflect.Descriptor, error)
+}
+
+// Compile compiles the given .proto files, which are looked for within each of
+// the import paths of the file system, as are any files they import. The
+// well-known types, such as google/protobuf/timestamp.proto, can be imported
+// without being present.
+func Compile(
+	fsys fs.FS, files []string, importPaths []string) (Descriptors, error) {
+	c := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{
+			ImportPaths: importPaths,
+			Accessor: func(name string) (io.ReadCloser, error) {
+				return fsys.Open(path.Clean(name))
+			},
+		}),
+	}
+	fds, err := c.Compile(context.Background(), files...)
+	if err != nil {
+		return nil, fmt.Errorf("Could not compile proto files: %s", err)
+	}
+	return fds.AsResolver(), nil
+}
+
+// FindMethod finds the descriptor of a method, given the fully qualified name
+// of its service and the name of the method.
+func FindMethod(
+	d Descriptors, service, method string) (protoreflect.MethodDescriptor, error) {
+	desc, err := d.FindDescriptorByName(protoreflect.FullName(service))
+	if err != nil {
+		return nil, fmt.Errorf("Service \"%s\" does not exist.", service)
+	}
+	sd, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("\"%s\" is not a service.", service)
+	}
+	md := sd.Methods().ByName(protoreflect.Name(method))
+	if md == nil {
+		return nil, fmt.Errorf(
+			"Method \"%s\" does not exist on service \"%s\".", method, service)
+	}
+	return md, nil
+}
+
+// Marshal encodes the JSON request message as the input of the method. Methods
+// that take a stream of messages expect a JSON array, with each element being
+// sent as a message of its own.
+func Marshal(md protoreflect.MethodDescriptor, j []byte) ([][]byte, error) {
+	j = bytes.TrimSpace(j)
+	if len(j) == 0 {
+		j = []byte("{}")
+	}
+
+	raws := []json.RawMessage{j}
+	if md.IsStreamingClient() && j[0] == '[' {
+		raws = nil
+		if err := json.Unmarshal(j, &raws); err != nil {
+			return nil, fmt.Errorf("Message is not valid JSON: %s", err)
+		}
+	}
+
+	var msgs [][]byte
+	for _, raw := range raws {
+		m := dynamicpb.NewMessage(md.Input())
+		if err := protojson.Unmarshal(raw, m); err != nil {
+			return nil, fmt.Errorf("Could not encode message as %s: %s",
+				md.Input().FullName(), err)
+		}
+		b, err := proto.Marshal(m)
+		if err != nil {
+			return nil, fmt.Errorf("Could not encode message as %s: %s",
+				md.Input().FullName(), err)
+		}
+		msgs = append(msgs, b)
+	}
+	return msgs, nil
+}
+
+// Unmarshal decodes each response message as the output of the method,
+// returning it as JSON. Methods that return a stream of messages are returned
+// as a JSON array.
+func Unmarshal(md protoreflect.MethodDescriptor, msgs [][]byte) ([]byte, error) {
+	var raws []json.RawMessage
+	for _, b := range msgs {
+		m := dynamicpb.NewMessage(md.Output())
+		if err := proto.Unmarshal(b, m); err != nil {
+			return nil, fmt.Errorf("Could not decode message as %s: %s",
+				md.Output().FullName(), err)
+		}
+		j, err := protojson.Marshal(m)
+		if err != nil {
+			return nil, fmt.Errorf("Could not decode message as %s: %s",
+				md.Output().FullName(), err)
+		}
+		raws = append(raws, j)
+	}
+
+	// The output of protojson is deliberately unstable, so it is compacted to
+	// be the same each time.
+	var j []byte
+	switch {
+	case md.IsStreamingServer():
+		if raws == nil {
+			raws = []json.RawMessage{}
+		}
+		j, _ = json.Marshal(raws)
+	case len(raws) == 0:
+		return nil, nil
+	default:
+		j = raws[0]
+	}
+
+	var b bytes.Buffer
+	if err := json.Compact(&b, j); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
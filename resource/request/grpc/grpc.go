@@ -0,0 +1,236 @@
+// Package grpc calls gRPC methods over HTTP/2, with messages being encoded from
+// and decoded to JSON using descriptors that have either been compiled from
+// .proto files or fetched from the server using server reflection.
+package grpc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ContentType is the content type of gRPC requests and responses.
+	ContentType = "application/grpc"
+
+	// maxMessageSize is the largest message that will be read from a
+	// response, to avoid allocating whatever length a broken server sends.
+	maxMessageSize = 64 << 20
+)
+
+// Code is a gRPC status code, sent by the server in the grpc-status trailer.
+type Code int
+
+// The status codes defined by gRPC.
+const (
+	OK Code = iota
+	Canceled
+	Unknown
+	InvalidArgument
+	DeadlineExceeded
+	NotFound
+	AlreadyExists
+	PermissionDenied
+	ResourceExhausted
+	FailedPrecondition
+	Aborted
+	OutOfRange
+	Unimplemented
+	Internal
+	Unavailable
+	DataLoss
+	Unauthenticated
+)
+
+var codeNames = [...]string{
+	"OK",
+	"CANCELLED",
+	"UNKNOWN",
+	"INVALID_ARGUMENT",
+	"DEADLINE_EXCEEDED",
+	"NOT_FOUND",
+	"ALREADY_EXISTS",
+	"PERMISSION_DENIED",
+	"RESOURCE_EXHAUSTED",
+	"FAILED_PRECONDITION",
+	"ABORTED",
+	"OUT_OF_RANGE",
+	"UNIMPLEMENTED",
+	"INTERNAL",
+	"UNAVAILABLE",
+	"DATA_LOSS",
+	"UNAUTHENTICATED",
+}
+
+// String returns the name of the code as it is written in the gRPC spec.
+func (c Code) String() string {
+	if c < 0 || int(c) >= len(codeNames) {
+		return fmt.Sprintf("CODE(%d)", int(c))
+	}
+	return codeNames[c]
+}
+
+// HTTPStatus returns the HTTP status code closest in meaning to the gRPC code,
+// using the same mapping as grpc-gateway.
+func (c Code) HTTPStatus() int {
+	switch c {
+	case OK:
+		return http.StatusOK
+	case Canceled:
+		return 499
+	case InvalidArgument, FailedPrecondition, OutOfRange:
+		return http.StatusBadRequest
+	case DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case NotFound:
+		return http.StatusNotFound
+	case AlreadyExists, Aborted:
+		return http.StatusConflict
+	case PermissionDenied:
+		return http.StatusForbidden
+	case Unauthenticated:
+		return http.StatusUnauthorized
+	case ResourceExhausted:
+		return http.StatusTooManyRequests
+	case Unimplemented:
+		return http.StatusNotImplemented
+	case Unavailable:
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+// Status is the outcome of a call, as sent by the server once the response
+// messages have been sent.
+type Status struct {
+	Code    Code
+	Message string
+}
+
+// String returns the name of the status code.
+func (s Status) String() string {
+	return s.Code.String()
+}
+
+// Error returns the code along with the message sent by the server, so that a
+// status other than OK can be returned as an error.
+func (s Status) Error() string {
+	if s.Message == "" {
+		return s.Code.String()
+	}
+	return fmt.Sprintf("%s: %s", s.Code, s.Message)
+}
+
+// MarshalJSON writes the code using its name rather than its number.
+func (s Status) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Code    string `json:"code"`
+		Message string `json:"message,omitempty"`
+	}{s.Code.String(), s.Message})
+}
+
+// StatusFromResponse returns the status of a call once its response body has
+// been read. The status is usually sent in the trailers, but servers that fail
+// a call before sending any messages send it with the headers instead. False
+// is returned if the response does not contain a status.
+func StatusFromResponse(r *http.Response) (Status, bool) {
+	h := r.Trailer
+	if h.Get("Grpc-Status") == "" {
+		h = r.Header
+	}
+	v := h.Get("Grpc-Status")
+	if v == "" {
+		return Status{}, false
+	}
+	c, err := strconv.Atoi(v)
+	if err != nil {
+		return Status{Code: Unknown, Message: fmt.Sprintf(
+			"Invalid grpc-status \"%s\"", v)}, true
+	}
+
+	// The message is percent encoded, although not quite in the same way as a
+	// query, as a plus is left as it is.
+	msg := h.Get("Grpc-Message")
+	if m, err := url.PathUnescape(msg); err == nil {
+		msg = m
+	}
+	return Status{Code: Code(c), Message: msg}, true
+}
+
+// IsResponse checks whether the response was sent by a gRPC server, rather
+// than by something in front of it such as a proxy.
+func IsResponse(r *http.Response) bool {
+	ct := r.Header.Get("Content-Type")
+	return ct == ContentType || strings.HasPrefix(ct, ContentType+"+") ||
+		strings.HasPrefix(ct, ContentType+";")
+}
+
+// Frame prefixes each message with its length, as messages are sent over the
+// stream of a call. Messages are never compressed.
+func Frame(msgs ...[]byte) []byte {
+	var b bytes.Buffer
+	for _, m := range msgs {
+		var p [5]byte
+		binary.BigEndian.PutUint32(p[1:], uint32(len(m)))
+		b.Write(p[:])
+		b.Write(m)
+	}
+	return b.Bytes()
+}
+
+// ReadFrames reads each length prefixed message from the body of a response
+// until it ends. The encoding is that of the grpc-encoding header, used by
+// messages that have been compressed.
+func ReadFrames(r io.Reader, encoding string) ([][]byte, error) {
+	var msgs [][]byte
+	for {
+		var p [5]byte
+		if _, err := io.ReadFull(r, p[:]); err != nil {
+			if err == io.EOF {
+				return msgs, nil
+			}
+			return nil, fmt.Errorf("Could not read message: %s", err)
+		}
+
+		n := binary.BigEndian.Uint32(p[1:])
+		if n > maxMessageSize {
+			return nil, fmt.Errorf(
+				"Message of %d bytes is larger than the limit of %d.",
+				n, maxMessageSize)
+		}
+		m := make([]byte, n)
+		if _, err := io.ReadFull(r, m); err != nil {
+			return nil, fmt.Errorf("Could not read message: %s", err)
+		}
+
+		if p[0] == 1 {
+			var err error
+			if m, err = decompress(m, encoding); err != nil {
+				return nil, err
+			}
+		}
+		msgs = append(msgs, m)
+	}
+}
+
+// decompress decompresses a message that has been sent compressed.
+func decompress(m []byte, encoding string) ([]byte, error) {
+	if encoding != "gzip" {
+		return nil, fmt.Errorf(
+			"Message is compressed using unsupported encoding \"%s\".", encoding)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(m))
+	if err != nil {
+		return nil, fmt.Errorf("Could not decompress message: %s", err)
+	}
+	defer zr.Close()
+	return ioutil.ReadAll(io.LimitReader(zr, maxMessageSize))
+}
@@ -0,0 +1,63 @@
+package grpc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestReadFrames(t *testing.T) {
+	var z bytes.Buffer
+	zw := gzip.NewWriter(&z)
+	zw.Write([]byte("compressed"))
+	zw.Close()
+
+	b := Frame([]byte("one"), []byte{})
+	b = append(b, 1, 0, 0, 0, byte(z.Len()))
+	b = append(b, z.Bytes()...)
+
+	msgs, err := ReadFrames(bytes.NewReader(b), "gzip")
+	assert.Nil(t, err)
+	assert.Equal(t, [][]byte{[]byte("one"), {}, []byte("compressed")}, msgs)
+
+	_, err = ReadFrames(bytes.NewReader(b), "")
+	assert.EqualError(t, err,
+		"Message is compressed using unsupported encoding \"\".")
+
+	_, err = ReadFrames(bytes.NewReader(b[:6]), "")
+	assert.EqualError(t, err, "Could not read message: unexpected EOF")
+}
+
+func TestStatusFromResponse(t *testing.T) {
+	r := &http.Response{Header: http.Header{}, Trailer: http.Header{}}
+	_, ok := StatusFromResponse(r)
+	assert.False(t, ok)
+
+	// Calls that fail straight away send the status with the headers.
+	r.Header.Set("Grpc-Status", "16")
+	s, ok := StatusFromResponse(r)
+	assert.True(t, ok)
+	assert.Equal(t, Unauthenticated, s.Code)
+	assert.Equal(t, http.StatusUnauthorized, s.Code.HTTPStatus())
+
+	r.Trailer.Set("Grpc-Status", "5")
+	r.Trailer.Set("Grpc-Message", "user 1+1 not%20found")
+	s, _ = StatusFromResponse(r)
+	assert.Equal(t, "NOT_FOUND: user 1+1 not found", s.Error())
+}
+
+func TestParseReflectionError(t *testing.T) {
+	e := protowire.AppendTag(nil, errorCode, protowire.VarintType)
+	e = protowire.AppendVarint(e, uint64(NotFound))
+	e = append(e, stringField(errorMessage, "unknown symbol")...)
+
+	b := protowire.AppendTag(nil, respError, protowire.BytesType)
+	b = protowire.AppendBytes(b, e)
+
+	_, err := parseReflectionResponse(b)
+	assert.Equal(t, Status{Code: NotFound, Message: "unknown symbol"}, err)
+}
@@ -0,0 +1,227 @@
+package grpc
+
+import (
+	"fmt"
+	"net/http"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// reflectionServices are the services used for server reflection, with older
+// servers only supporting v1alpha.
+var reflectionServices = []string{
+	"grpc.reflection.v1.ServerReflection",
+	"grpc.reflection.v1alpha.ServerReflection",
+}
+
+// Field numbers of the messages used for server reflection. The messages are
+// small enough that they are encoded and decoded by hand, rather than needing
+// the descriptors of the reflection service itself.
+const (
+	reqFileByFilename       protowire.Number = 3
+	reqFileContainingSymbol protowire.Number = 4
+
+	respFileDescriptor protowire.Number = 4
+	respError          protowire.Number = 7
+
+	fileDescriptorProto protowire.Number = 1
+
+	errorCode    protowire.Number = 1
+	errorMessage protowire.Number = 2
+)
+
+// RequestFunc builds the request used to make a call to the method at the
+// given path, such as "/users.v1.Users/Get", sending the given body.
+type RequestFunc func(path string, body []byte) (*http.Request, error)
+
+// Reflect fetches the descriptors of a service from the server using server
+// reflection, along with those of each file that it depends on. Requests are
+// built using newRequest, so that the same headers and auth used to make the
+// call are used to fetch its descriptors.
+func Reflect(
+	client *http.Client, newRequest RequestFunc, service string) (Descriptors, error) {
+	var err error
+	for _, rs := range reflectionServices {
+		r := reflector{
+			client:     client,
+			newRequest: newRequest,
+			path:       fmt.Sprintf("/%s/ServerReflectionInfo", rs),
+		}
+
+		var d Descriptors
+		if d, err = r.service(service); err == nil {
+			return d, nil
+		}
+		if s, ok := err.(Status); !ok || s.Code != Unimplemented {
+			break
+		}
+	}
+	return nil, fmt.Errorf(
+		"Could not fetch descriptors using server reflection: %s", err)
+}
+
+// reflector makes calls to a single version of the reflection service.
+type reflector struct {
+	client     *http.Client
+	newRequest RequestFunc
+	path       string
+}
+
+// service fetches the file that defines the given service, followed by any
+// files that it imports which the server did not send along with it.
+func (r reflector) service(name string) (Descriptors, error) {
+	var (
+		files = map[string]*descriptorpb.FileDescriptorProto{}
+		order []string
+	)
+	add := func(fds []*descriptorpb.FileDescriptorProto) {
+		for _, fd := range fds {
+			if _, ok := files[fd.GetName()]; !ok {
+				files[fd.GetName()] = fd
+				order = append(order, fd.GetName())
+			}
+		}
+	}
+
+	fds, err := r.call(stringField(reqFileContainingSymbol, name))
+	if err != nil {
+		return nil, err
+	}
+	add(fds)
+
+	for i := 0; i < len(order); i++ {
+		for _, dep := range files[order[i]].GetDependency() {
+			if _, ok := files[dep]; ok {
+				continue
+			}
+			fds, err := r.call(stringField(reqFileByFilename, dep))
+			if err != nil {
+				return nil, err
+			}
+			add(fds)
+		}
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+	for _, n := range order {
+		set.File = append(set.File, files[n])
+	}
+	d, err := protodesc.NewFiles(set)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid descriptors: %s", err)
+	}
+	return d, nil
+}
+
+// call sends a single request to the reflection service, returning the file
+// descriptors sent back.
+func (r reflector) call(msg []byte) ([]*descriptorpb.FileDescriptorProto, error) {
+	req, err := r.newRequest(r.path, Frame(msg))
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if !IsResponse(resp) {
+		return nil, fmt.Errorf("Server responded with %s.", resp.Status)
+	}
+	msgs, err := ReadFrames(resp.Body, resp.Header.Get("Grpc-Encoding"))
+	if err != nil {
+		return nil, err
+	}
+	if s, ok := StatusFromResponse(resp); ok && s.Code != OK {
+		return nil, s
+	}
+	if len(msgs) == 0 {
+		return nil, fmt.Errorf("Server did not send a response.")
+	}
+	return parseReflectionResponse(msgs[0])
+}
+
+// parseReflectionResponse decodes the file descriptors from a response sent
+// by the reflection service, or the error if one was sent instead.
+func parseReflectionResponse(b []byte) ([]*descriptorpb.FileDescriptorProto, error) {
+	var (
+		fds    []*descriptorpb.FileDescriptorProto
+		failed *Status
+	)
+	err := eachField(b, func(num protowire.Number, v []byte) error {
+		switch num {
+		case respFileDescriptor:
+			return eachField(v, func(num protowire.Number, v []byte) error {
+				if num != fileDescriptorProto {
+					return nil
+				}
+				fd := &descriptorpb.FileDescriptorProto{}
+				if err := proto.Unmarshal(v, fd); err != nil {
+					return err
+				}
+				fds = append(fds, fd)
+				return nil
+			})
+		case respError:
+			failed = &Status{}
+			return eachField(v, func(num protowire.Number, v []byte) error {
+				switch num {
+				case errorCode:
+					c, _ := protowire.ConsumeVarint(v)
+					failed.Code = Code(c)
+				case errorMessage:
+					failed.Message = string(v)
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Invalid reflection response: %s", err)
+	}
+	if failed != nil {
+		return nil, *failed
+	}
+	return fds, nil
+}
+
+// stringField encodes a message containing only the given string field.
+func stringField(num protowire.Number, s string) []byte {
+	b := protowire.AppendTag(nil, num, protowire.BytesType)
+	return protowire.AppendString(b, s)
+}
+
+// eachField calls f with the number and value of each field within an encoded
+// message. The value of a length delimited field is its contents, whereas
+// the value of any other field is left encoded.
+func eachField(b []byte, f func(protowire.Number, []byte) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		var v []byte
+		if typ == protowire.BytesType {
+			if v, n = protowire.ConsumeBytes(b); n < 0 {
+				return protowire.ParseError(n)
+			}
+		} else {
+			if n = protowire.ConsumeFieldValue(num, typ, b); n < 0 {
+				return protowire.ParseError(n)
+			}
+			v = b[:n]
+		}
+		if err := f(num, v); err != nil {
+			return err
+		}
+		b = b[n:]
+	}
+	return nil
+}
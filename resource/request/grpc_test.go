@@ -0,0 +1,208 @@
+package request
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/hazbo/httpu/resource/request/grpc"
+	utils "github.com/hazbo/httpu/utils/common"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// greeter compiles the service implemented by the test server.
+func greeter(t *testing.T) protoreflect.ServiceDescriptor {
+	d, err := grpc.Compile(utils.DirFS("../../"), []string{"greet.proto"},
+		[]string{"projects/test_project/protos"})
+	assert.Nil(t, err)
+	sd, err := d.FindDescriptorByName("greet.v1.Greeter")
+	assert.Nil(t, err)
+	return sd.(protoreflect.ServiceDescriptor)
+}
+
+// grpcServer starts an h2c server implementing the greeter service, along with
+// the v1alpha reflection service. Each call records the metadata it was sent.
+func grpcServer(t *testing.T, md *http.Header) *httptest.Server {
+	sd := greeter(t)
+	files := map[string]protoreflect.FileDescriptor{}
+	for f := sd.ParentFile(); f != nil; {
+		files[f.Path()] = f
+		if f.Imports().Len() == 0 {
+			break
+		}
+		f = f.Imports().Get(0).FileDescriptor
+	}
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, 2, r.ProtoMajor)
+			*md = r.Header.Clone()
+			w.Header().Set("Content-Type", grpc.ContentType)
+			w.Header().Set("Trailer", "Grpc-Status, Grpc-Message")
+
+			msgs, err := grpc.ReadFrames(r.Body, "")
+			assert.Nil(t, err)
+			assert.Len(t, msgs, 1)
+
+			var (
+				out    [][]byte
+				status = grpc.Status{}
+			)
+			switch r.URL.Path {
+			case "/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo":
+				// Only the requested file is sent, so that its dependencies
+				// need to be requested too.
+				num, _, n := protowire.ConsumeTag(msgs[0])
+				name, _ := protowire.ConsumeString(msgs[0][n:])
+				f := files[name]
+				if num == 4 {
+					f = sd.ParentFile()
+				}
+				fdp, _ := proto.Marshal(protodesc.ToFileDescriptorProto(f))
+				fdr := protowire.AppendTag(nil, 1, protowire.BytesType)
+				fdr = protowire.AppendBytes(fdr, fdp)
+				resp := protowire.AppendTag(nil, 4, protowire.BytesType)
+				out = append(out, protowire.AppendBytes(resp, fdr))
+			case "/greet.v1.Greeter/Hello":
+				m := sd.Methods().ByName("Hello")
+				in := dynamicpb.NewMessage(m.Input())
+				assert.Nil(t, proto.Unmarshal(msgs[0], in))
+				name := in.Get(m.Input().Fields().ByName("name")).String()
+				if name == "" {
+					status = grpc.Status{
+						Code: grpc.InvalidArgument, Message: "name is required"}
+					break
+				}
+				reply := dynamicpb.NewMessage(m.Output())
+				reply.Set(m.Output().Fields().ByName("message"),
+					protoreflect.ValueOfString("Hello "+name))
+				b, _ := proto.Marshal(reply)
+				out = append(out, b)
+			case "/greet.v1.Greeter/Count":
+				m := sd.Methods().ByName("Count")
+				in := dynamicpb.NewMessage(m.Input())
+				assert.Nil(t, proto.Unmarshal(msgs[0], in))
+				to := in.Get(m.Input().Fields().ByName("to")).Int()
+				for i := int64(1); i <= to; i++ {
+					reply := dynamicpb.NewMessage(m.Output())
+					reply.Set(m.Output().Fields().ByName("message"),
+						protoreflect.ValueOfString(fmt.Sprint(i)))
+					b, _ := proto.Marshal(reply)
+					out = append(out, b)
+				}
+			default:
+				status = grpc.Status{Code: grpc.Unimplemented}
+			}
+
+			w.Write(grpc.Frame(out...))
+			w.Header().Set("Grpc-Status", fmt.Sprint(int(status.Code)))
+			w.Header().Set("Grpc-Message", url.PathEscape(status.Message))
+		}))
+	server.Config.Protocols = new(http.Protocols)
+	server.Config.Protocols.SetHTTP1(true)
+	server.Config.Protocols.SetUnencryptedHTTP2(true)
+	server.Start()
+	return server
+}
+
+// readBody reads the whole body of the response.
+func readBody(t *testing.T, resp *http.Response) string {
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	return string(b)
+}
+
+func TestMakeGRPC(t *testing.T) {
+	var md http.Header
+	server := grpcServer(t, &md)
+	defer server.Close()
+
+	h := http.Header{}
+	h.Set("X-Tenant", "acme")
+	r := Request{
+		Kind:  KindGRPC,
+		Name:  "greet",
+		Scope: NewScope(utils.DirFS("../../")),
+		Spec: RequestSpec{
+			Headers: h,
+			GRPC: &GRPC{
+				Service:     "greet.v1.Greeter",
+				Method:      "Hello",
+				Message:     json.RawMessage(`{"name": "httpu", "tags": ["a"]}`),
+				ProtoFiles:  []string{"greet.proto"},
+				ImportPaths: []string{"projects/test_project/protos"},
+			},
+		},
+	}
+
+	u, _ := url.Parse(server.URL)
+	resp, _, err := r.Make(*u)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "acme", md.Get("X-Tenant"))
+	assert.Equal(t, grpc.ContentType, md.Get("Content-Type"))
+
+	assert.Equal(t, `{"message":"Hello httpu"}`, readBody(t, resp))
+}
+
+func TestMakeGRPCReflection(t *testing.T) {
+	var md http.Header
+	server := grpcServer(t, &md)
+	defer server.Close()
+
+	r := Request{
+		Kind: KindGRPC,
+		Name: "greet",
+		Spec: RequestSpec{
+			GRPC: &GRPC{Service: "greet.v1.Greeter", Method: "Hello"},
+			Variants: Variants{
+				Variant{Name: "count", GRPC: &GRPC{Method: "Count"},
+					Message: json.RawMessage(`{"to": 3}`)},
+				Variant{Name: "anonymous"},
+			},
+		},
+	}
+	u, _ := url.Parse(server.URL)
+
+	v, _ := r.Variant("count")
+	resp, _, err := r.MakeWithVariant(*u, &v)
+	assert.Nil(t, err)
+	assert.Equal(t,
+		`[{"message":"1"},{"message":"2"},{"message":"3"}]`, readBody(t, resp))
+
+	// Calls that fail have the status written as the body instead.
+	v, _ = r.Variant("anonymous")
+	resp, _, err = r.MakeWithVariant(*u, &v)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t,
+		`{"code":"INVALID_ARGUMENT","message":"name is required"}`,
+		readBody(t, resp))
+
+	r.Spec.GRPC.Method = "Missing"
+	_, _, err = r.Make(*u)
+	assert.EqualError(t, err, "Could not construct request: "+
+		"Method \"Missing\" does not exist on service \"greet.v1.Greeter\".")
+}
+
+func TestGRPCArgs(t *testing.T) {
+	r := Request{Spec: RequestSpec{GRPC: &GRPC{Message: json.RawMessage(`{}`)}}}
+	a, _ := ParseArgs([]string{"name=httpu"})
+	assert.Nil(t, a.Apply(&r, nil))
+	assert.JSONEq(t, `{"name": "httpu"}`, string(r.GRPC(nil).Message))
+
+	v := Variant{Message: json.RawMessage(`{"to": 1}`)}
+	a, _ = ParseArgs([]string{"to=2"})
+	assert.Nil(t, a.Apply(&r, &v))
+	assert.JSONEq(t, `{"to": 2}`, string(r.GRPC(&v).Message))
+}
@@ -1,45 +1,90 @@
 package request
 
 import (
-	"github.com/hazbo/httpu/env"
+	"io/fs"
+
 	"github.com/hazbo/httpu/resource/request/headers"
-	"github.com/hazbo/httpu/stash"
 )
 
 type parser func(string) string
 
 // Update modifies the request spec to include any data that has recently been
 // added to the stash. If new values exist, the values within the request spec
-// will be added at this point.
+// will be added at this point. The default scope is used, as it is for any
+// request without a scope of its own.
 func (rs *RequestSpec) Update() {
+	rs.UpdateWith(DefaultScope())
+}
+
+// UpdateWith updates the request spec in the same way as Update, with data
+// files being read from and variables being replaced using the given scope.
+func (rs *RequestSpec) UpdateWith(s *Scope) {
 	rs.addFormheader()
-	rs.loadDataFiles()
-	rs.parseEnvVars()
-	rs.parseStashVars()
+	rs.loadDataFiles(s.FS)
+	parseVars(s.parse, rs)
+}
+
+// Update modifies the spec of the request in the same way as RequestSpec.Update,
+// using the scope of the request.
+func (r *Request) Update() {
+	r.Spec.UpdateWith(r.scope())
+}
+
+// Update replaces any variables within the variant, in the same way that
+// RequestSpec.Update does for the variants it holds. This is needed when the
+// variant has been taken from the request before the spec was updated.
+func (v *Variant) Update() {
+	v.UpdateWith(DefaultScope())
+}
+
+// UpdateWith updates the variant in the same way as Update, using the given
+// scope.
+func (v *Variant) UpdateWith(s *Scope) {
+	v.loadDataFiles(s.FS)
+	parseVariantVars(s.parse, v)
 }
 
 // addFormHeader adds a spesefic header to the request if form data has been
 // attached to it. It checks to make sure that the form data slice is greater
 // than 0 to do this.
 func (rs *RequestSpec) addFormheader() {
-	if len(rs.FormData) > 0 {
+	if len(rs.FormData) > 0 && len(rs.Multipart) == 0 {
 		rs.Headers[headers.ContentType] = []string{
 			"application/x-www-form-urlencoded"}
 	}
 }
 
 // loadDataFiles loads in any request data from seperate files for the given
-// request spec.
-func (rs *RequestSpec) loadDataFiles() {
+// request spec, along with that of each of its variants.
+func (rs *RequestSpec) loadDataFiles(fsys fs.FS) {
 	if rs.Data.FromFile != "" {
-		rs.Data.loadContents()
+		rs.Data.loadContents(fsys)
+	}
+	if rs.GraphQL != nil && rs.GraphQL.Query.FromFile != "" {
+		rs.GraphQL.Query.loadContents(fsys)
+	}
+	for i := range rs.Variants {
+		rs.Variants[i].loadDataFiles(fsys)
+	}
+}
+
+// loadDataFiles loads in any request data and GraphQL query of the variant
+// from seperate files.
+func (v *Variant) loadDataFiles(fsys fs.FS) {
+	if v.Data.FromFile != "" {
+		v.Data.loadContents(fsys)
+	}
+	if v.GraphQL != nil && v.GraphQL.Query.FromFile != "" {
+		v.GraphQL.Query.loadContents(fsys)
 	}
 }
 
 func parseVars(parse parser, rs *RequestSpec) {
 	rs.Uri = parse(rs.Uri)
 	rs.Method = parse(rs.Method)
-	rs.Data.contents = []byte(parse(rs.Data.String()))
+	if !rs.Data.Binary {
+		rs.Data.contents = []byte(parse(rs.Data.String()))
+	}
 
 	for fdk, uv := range rs.FormData {
 		for fdks, uvs := range uv {
@@ -47,37 +92,108 @@ func parseVars(parse parser, rs *RequestSpec) {
 		}
 	}
 
+	parseMultipart(parse, rs.Multipart)
+
+	for qk, qv := range rs.Query {
+		for qks, qvs := range qv {
+			rs.Query[qk][qks] = parse(qvs)
+		}
+	}
+
 	for k, _ := range rs.Headers {
 		rs.Headers[k][0] = parse(rs.Headers[k][0])
 	}
 
+	for pk, pv := range rs.PathParams {
+		rs.PathParams[pk] = parse(pv)
+	}
+
+	if rs.Auth != nil {
+		rs.Auth.Parse(parse)
+	}
+
+	if rs.Sign != nil {
+		rs.Sign.Parse(parse)
+	}
+
+	if rs.GraphQL != nil {
+		rs.GraphQL.parse(parse)
+	}
+
+	if rs.GRPC != nil {
+		rs.GRPC.parse(parse)
+	}
+
+	if rs.Transport != nil {
+		rs.Transport.parse(parse)
+	}
+
+	if rs.Proxy != nil {
+		rs.Proxy.parse(parse)
+	}
+
 	// Do the same as above, for all variants for the given request spec.
 	for vi, _ := range rs.Variants {
-		rs.Variants[vi].Path = parse(rs.Variants[vi].Path)
-		rs.Variants[vi].Data.contents = []byte(parse(rs.Variants[vi].Data.String()))
+		parseVariantVars(parse, &rs.Variants[vi])
+	}
+}
 
-		for i, _ := range rs.Variants[vi].Headers {
-			for t, _ := range rs.Variants[vi].Headers[i] {
-				rs.Variants[vi].Headers[i][t] = parse(rs.Variants[vi].Headers[i][t])
-			}
+func parseVariantVars(parse parser, v *Variant) {
+	v.Path = parse(v.Path)
+	v.Method = parse(v.Method)
+	if !v.Data.Binary {
+		v.Data.contents = []byte(parse(v.Data.String()))
+	}
+
+	for i, _ := range v.Headers {
+		for t, _ := range v.Headers[i] {
+			v.Headers[i][t] = parse(v.Headers[i][t])
 		}
+	}
 
-		for fdk, uv := range rs.Variants[vi].FormData {
-			for fdks, uvs := range uv {
-				rs.Variants[vi].FormData[fdk][fdks] = parse(uvs)
-			}
+	for fdk, uv := range v.FormData {
+		for fdks, uvs := range uv {
+			v.FormData[fdk][fdks] = parse(uvs)
 		}
 	}
-}
 
-// parseEnvVars goes through each possible instance of an environment variable
-// existing and replaces the variable name with the new value if it does exist.
-func (rs *RequestSpec) parseEnvVars() {
-	parseVars(env.Parse, rs)
+	parseMultipart(parse, v.Multipart)
+
+	for qk, qv := range v.Query {
+		for qks, qvs := range qv {
+			v.Query[qk][qks] = parse(qvs)
+		}
+	}
+
+	for pk, pv := range v.PathParams {
+		v.PathParams[pk] = parse(pv)
+	}
+
+	if v.Auth != nil {
+		v.Auth.Parse(parse)
+	}
+
+	if v.Sign != nil {
+		v.Sign.Parse(parse)
+	}
+
+	if v.GraphQL != nil {
+		v.GraphQL.parse(parse)
+	}
+
+	if v.GRPC != nil {
+		v.GRPC.parse(parse)
+	}
+
+	if len(v.Message) > 0 {
+		v.Message = []byte(parse(string(v.Message)))
+	}
 }
 
-// parseStashVars goes through each possible instance of a stash variable
-// existing and replaces the variable name with the new value if it does exist.
-func (rs *RequestSpec) parseStashVars() {
-	parseVars(stash.Parse, rs)
+func parseMultipart(parse parser, mfs MultipartFields) {
+	for i, _ := range mfs {
+		mfs[i].Value = parse(mfs[i].Value)
+		mfs[i].File = parse(mfs[i].File)
+		mfs[i].Filename = parse(mfs[i].Filename)
+	}
 }
@@ -0,0 +1,199 @@
+package request
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"mime/multipart"
+	"net/textproto"
+	"path/filepath"
+	"sync/atomic"
+
+	utils "github.com/hazbo/httpu/utils/common"
+)
+
+// MultipartField represents a single part of a multipart/form-data request
+// body. A field is either a text field with a value, or a file part that is
+// read from a file relative to the project.
+type MultipartField struct {
+	Name        string `json:"name"`
+	Value       string `json:"value"`
+	File        string `json:"file"`
+	Filename    string `json:"filename"`
+	ContentType string `json:"contentType"`
+}
+
+// IsFile checks to see if the field is a file part rather than a text field.
+func (mf MultipartField) IsFile() bool {
+	return mf.File != ""
+}
+
+// String returns a short description of the field, as shown in the request
+// view. The contents of file parts are not included.
+func (mf MultipartField) String() string {
+	if !mf.IsFile() {
+		return fmt.Sprintf("%s: %s", mf.Name, mf.Value)
+	}
+	return fmt.Sprintf("%s: @%s (%s, %s)",
+		mf.Name, mf.File, mf.filename(), mf.contentType())
+}
+
+// filename returns the filename sent for a file part, which is the name of the
+// file itself unless one has been set explicitly.
+func (mf MultipartField) filename() string {
+	if mf.Filename != "" {
+		return mf.Filename
+	}
+	return filepath.Base(mf.File)
+}
+
+// contentType returns the content type sent for a file part.
+func (mf MultipartField) contentType() string {
+	if mf.ContentType != "" {
+		return mf.ContentType
+	}
+	return "application/octet-stream"
+}
+
+// path returns the name of the file for a file part within the file system of
+// the project.
+func (mf MultipartField) path() string {
+	return utils.CleanPath(mf.File)
+}
+
+// MultipartFields represents each part of a multipart/form-data request body.
+type MultipartFields []MultipartField
+
+// Set sets the value of the text field with the given name, adding the field
+// if it does not already exist.
+func (mfs *MultipartFields) Set(name, value string) {
+	for i, mf := range *mfs {
+		if mf.Name == name && !mf.IsFile() {
+			(*mfs)[i].Value = value
+			return
+		}
+	}
+	*mfs = append(*mfs, MultipartField{Name: name, Value: value})
+}
+
+// body returns the encoded request body. The body is written as it is read, so
+// files are streamed from disk rather than being loaded into memory first.
+func (mfs MultipartFields) body(fsys fs.FS) (body, error) {
+	// Make sure that each file can be found before anything is sent, as errors
+	// that happen while the body is being streamed are less helpful. The size
+	// of each file is also needed to work out the length of the body.
+	sizes := map[string]int64{}
+	for _, mf := range mfs {
+		if !mf.IsFile() {
+			continue
+		}
+		fi, err := fs.Stat(fsys, mf.path())
+		if err != nil {
+			return body{}, fmt.Errorf("Could not open file for \"%s\": %s",
+				mf.Name, err)
+		}
+		sizes[mf.File] = fi.Size()
+	}
+
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+
+	// The length is worked out by writing the body without the contents of the
+	// files, using the same boundary, and then adding the size of each file.
+	var length int64
+	lw := multipart.NewWriter(&countingWriter{n: &length})
+	lw.SetBoundary(mw.Boundary())
+	err := mfs.write(lw, func(w io.Writer, mf MultipartField) error {
+		length += sizes[mf.File]
+		return nil
+	})
+	if err != nil {
+		return body{}, err
+	}
+
+	go func() {
+		pw.CloseWithError(mfs.write(mw, func(w io.Writer, mf MultipartField) error {
+			return copyFile(fsys, w, mf)
+		}))
+	}()
+
+	return body{
+		reader:      pr,
+		length:      length,
+		contentType: mw.FormDataContentType(),
+		streamed:    true,
+	}, nil
+}
+
+// copyFile copies the contents of the file for a file part to the writer.
+func copyFile(fsys fs.FS, w io.Writer, mf MultipartField) error {
+	f, err := fsys.Open(mf.path())
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// countingWriter counts the bytes written through it. If no writer is set, the
+// bytes are only counted.
+type countingWriter struct {
+	w io.Writer
+	n *int64
+}
+
+// Write is an implementation of io.Writer.
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	var err error
+	if cw.w != nil {
+		n, err = cw.w.Write(p)
+	}
+	atomic.AddInt64(cw.n, int64(n))
+	return n, err
+}
+
+// write writes each field to the multipart writer before closing it, using
+// the given func to write the contents of each file.
+func (mfs MultipartFields) write(
+	mw *multipart.Writer, file func(io.Writer, MultipartField) error) error {
+
+	for _, mf := range mfs {
+		if !mf.IsFile() {
+			if err := mw.WriteField(mf.Name, mf.Value); err != nil {
+				return err
+			}
+			continue
+		}
+
+		h := textproto.MIMEHeader{}
+		h.Set("Content-Disposition", fmt.Sprintf(
+			`form-data; name="%s"; filename="%s"`,
+			escapeQuotes(mf.Name), escapeQuotes(mf.filename())))
+		h.Set("Content-Type", mf.contentType())
+
+		w, err := mw.CreatePart(h)
+		if err != nil {
+			return err
+		}
+
+		if err := file(w, mf); err != nil {
+			return err
+		}
+	}
+	return mw.Close()
+}
+
+// escapeQuotes escapes a value used within the Content-Disposition header of a
+// part, in the same way as mime/multipart does.
+func escapeQuotes(s string) string {
+	var b []rune
+	for _, r := range s {
+		if r == '\\' || r == '"' {
+			b = append(b, '\\')
+		}
+		b = append(b, r)
+	}
+	return string(b)
+}
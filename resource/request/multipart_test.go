@@ -0,0 +1,64 @@
+package request
+
+import (
+	"bytes"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"strings"
+	"testing"
+
+	utils "github.com/hazbo/httpu/utils/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultipartBody(t *testing.T) {
+	mfs := MultipartFields{
+		MultipartField{Name: "title", Value: "hello"},
+		MultipartField{
+			Name:        "upload",
+			File:        "projects/test_project/data/test.json",
+			ContentType: "application/json",
+		},
+	}
+
+	mb, err := mfs.body(utils.DirFS("../../"))
+	assert.Nil(t, err)
+
+	mt, params, _ := mime.ParseMediaType(mb.contentType)
+	assert.Equal(t, "multipart/form-data", mt)
+
+	all, _ := ioutil.ReadAll(mb.reader)
+	assert.Equal(t, mb.length, int64(len(all)))
+
+	mr := multipart.NewReader(bytes.NewReader(all), params["boundary"])
+
+	p, _ := mr.NextPart()
+	b, _ := ioutil.ReadAll(p)
+	assert.Equal(t, "title", p.FormName())
+	assert.Equal(t, "hello", string(b))
+
+	p, _ = mr.NextPart()
+	b, _ = ioutil.ReadAll(p)
+	assert.Equal(t, "upload", p.FormName())
+	assert.Equal(t, "test.json", p.FileName())
+	assert.Equal(t, "application/json", p.Header.Get("Content-Type"))
+	assert.Equal(t, `{"error": "false"}`, strings.TrimSpace(string(b)))
+}
+
+func TestMultipartBodyMissingFile(t *testing.T) {
+	mfs := MultipartFields{
+		MultipartField{Name: "upload", File: "projects/test_project/data/none"},
+	}
+
+	_, err := mfs.body(utils.DirFS("../../"))
+	assert.NotNil(t, err)
+}
+
+func TestMultipartFieldsSet(t *testing.T) {
+	mfs := MultipartFields{MultipartField{Name: "title", Value: "a"}}
+	mfs.Set("title", "b")
+	mfs.Set("other", "c")
+	assert.Equal(t, "b", mfs[0].Value)
+	assert.Equal(t, "other", mfs[1].Name)
+}
@@ -0,0 +1,70 @@
+package request
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+)
+
+// pathParamRegexp matches a single path parameter placeholder within a uri or
+// path, such as {orderId}. Variables such as ${stash[id]} are not matched.
+var pathParamRegexp = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_.-]*)\}`)
+
+// pathParamNames returns the name of each path parameter placeholder within
+// the given uri or path, in the order in which they appear.
+func pathParamNames(s string) []string {
+	var names []string
+	for _, m := range pathParamRegexp.FindAllStringSubmatch(s, -1) {
+		if !containsString(names, m[1]) {
+			names = append(names, m[1])
+		}
+	}
+	return names
+}
+
+// expandPath replaces each path parameter placeholder with its value, escaped
+// so that it can only ever make up a single path segment. An error is returned
+// if any of the placeholders have not been bound to a value.
+func expandPath(s string, params map[string]string) (string, error) {
+	for _, n := range pathParamNames(s) {
+		if _, ok := params[n]; !ok {
+			return "", fmt.Errorf("Path parameter \"%s\" has not been set.", n)
+		}
+	}
+	return pathParamRegexp.ReplaceAllStringFunc(s, func(m string) string {
+		return url.PathEscape(params[m[1:len(m)-1]])
+	}), nil
+}
+
+// mergePathParams merges the path parameters of a request with those of a
+// variant, where those set by the variant take precedence.
+func mergePathParams(rp, vp map[string]string) map[string]string {
+	p := map[string]string{}
+	for k, v := range rp {
+		p[k] = v
+	}
+	for k, v := range vp {
+		p[k] = v
+	}
+	return p
+}
+
+// PathParams returns the name of each path parameter used by the request, and
+// by the given variant if there is one.
+func (r Request) PathParams(v *Variant) []string {
+	s := r.Spec.Uri
+	if v != nil {
+		s += v.Path
+	}
+	return pathParamNames(s)
+}
+
+// containsString checks to see if the given string exists within the slice.
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
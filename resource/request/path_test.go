@@ -0,0 +1,24 @@
+package request
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPathParamNames(t *testing.T) {
+	names := pathParamNames("/orders/{orderId}/items/{itemId}/${stash[x]}")
+	assert.Equal(t, []string{"orderId", "itemId"}, names)
+}
+
+func TestExpandPath(t *testing.T) {
+	p, err := expandPath("/orders/{orderId}/items/{itemId}", map[string]string{
+		"orderId": "a/b c",
+		"itemId":  "1",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "/orders/a%2Fb%20c/items/1", p)
+
+	_, err = expandPath("/orders/{orderId}", map[string]string{})
+	assert.NotNil(t, err)
+}
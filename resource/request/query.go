@@ -0,0 +1,42 @@
+package request
+
+import (
+	"net/url"
+)
+
+// mergeQuery merges the query parameters of a request with those of a variant.
+// Where both set a parameter with the same name, the values from the variant
+// replace those from the request, which still allows either to repeat a key.
+func mergeQuery(rq, vq url.Values) url.Values {
+	q := url.Values{}
+	for k, v := range rq {
+		q[k] = append([]string(nil), v...)
+	}
+	for k, v := range vq {
+		q[k] = append([]string(nil), v...)
+	}
+	return q
+}
+
+// fullURL returns the url for the request with the query parameters encoded
+// and added to any that were already written as part of the url itself.
+func (hr httpRequest) fullURL() (string, error) {
+	if len(hr.query) == 0 {
+		return hr.url, nil
+	}
+
+	u, err := url.Parse(hr.url)
+	if err != nil {
+		return "", err
+	}
+
+	q := u.Query()
+	for k, v := range hr.query {
+		for _, qv := range v {
+			q.Add(k, qv)
+		}
+	}
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
+}
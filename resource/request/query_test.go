@@ -0,0 +1,30 @@
+package request
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeQuery(t *testing.T) {
+	rq := url.Values{"page": {"1"}, "tag": {"a", "b"}}
+	vq := url.Values{"page": {"2"}}
+
+	q := mergeQuery(rq, vq)
+	assert.Equal(t, []string{"2"}, q["page"])
+	assert.Equal(t, []string{"a", "b"}, q["tag"])
+	assert.Equal(t, []string{"1"}, rq["page"], "the request should not change")
+}
+
+func TestFullURL(t *testing.T) {
+	hr := httpRequest{url: "http://example.com/search?sort=asc"}
+	u, err := hr.fullURL()
+	assert.Nil(t, err)
+	assert.Equal(t, "http://example.com/search?sort=asc", u)
+
+	hr.query = url.Values{"q": {"a b&c"}, "tag": {"x", "y"}}
+	u, err = hr.fullURL()
+	assert.Nil(t, err)
+	assert.Equal(t, "http://example.com/search?q=a+b%26c&sort=asc&tag=x&tag=y", u)
+}
@@ -4,37 +4,74 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"io/fs"
 	"io/ioutil"
 	"net/http"
 	"net/url"
-	"strings"
 	"time"
 
 	"github.com/buger/jsonparser"
+	"github.com/hazbo/httpu/prompt"
+	"github.com/hazbo/httpu/resource/request/auth"
 	"github.com/hazbo/httpu/resource/request/headers"
+	"github.com/hazbo/httpu/resource/request/signer"
 	"github.com/hazbo/httpu/stash"
 	utils "github.com/hazbo/httpu/utils/common"
+	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
-// requestData represents the request body that will be sent.
+// requestData represents the request body that will be sent, either loaded
+// from the file given by FromFile or given inline as contents.
+//
+// If Binary is set, the file given by FromFile is streamed from disk as it is
+// when the request is made, without being loaded into memory first or having
+// any variables replaced.
 type requestData struct {
 	FromFile string `json:"fromFile"`
+	Binary   bool   `json:"binary"`
 	contents []byte
 }
 
+// UnmarshalJSON allows the request data to be given inline as contents rather
+// than being loaded from a file, which is mostly useful for bodies that span
+// multiple lines within YAML and TOML resource files.
+func (rd *requestData) UnmarshalJSON(j []byte) error {
+	type Alias requestData
+	aux := &struct {
+		Contents *string `json:"contents"`
+		*Alias
+	}{
+		Alias: (*Alias)(rd),
+	}
+
+	if err := json.Unmarshal(j, &aux); err != nil {
+		return err
+	}
+	if aux.Contents != nil {
+		rd.contents = []byte(*aux.Contents)
+	}
+	return nil
+}
+
+// empty checks whether no request body has been set, either inline or from a
+// file.
+func (rd requestData) empty() bool {
+	return rd.FromFile == "" && len(rd.contents) == 0
+}
+
 // Strings returns the contents of the request body as a string.
 func (rd requestData) String() string {
 	return string(rd.contents)
 }
 
-// loadContents loads the contents of a text file to use as the request data
-// when passed to a request.
-func (rd *requestData) loadContents() error {
-	if len(rd.contents) > 0 {
+// loadContents loads the contents of a text file within the given file system
+// to use as the request data when passed to a request.
+func (rd *requestData) loadContents(fsys fs.FS) error {
+	if len(rd.contents) > 0 || rd.Binary {
 		return nil
 	}
-	b, err := ioutil.ReadFile(
-		fmt.Sprintf("%s/%s", utils.ProjectPath, rd.FromFile))
+	b, err := fs.ReadFile(fsys, utils.CleanPath(rd.FromFile))
 	if err != nil {
 		return err
 	}
@@ -46,13 +83,30 @@ func (rd *requestData) loadContents() error {
 // any variants. A request can be made this way individually if there are no
 // variants of it.
 type RequestSpec struct {
-	Uri         string            `json:"uri"`
-	Method      string            `json:"method"`
-	Data        requestData       `json:"data"`
-	FormData    url.Values        `json:"formData"`
-	Headers     http.Header       `json:"headers"`
-	Variants    Variants          `json:"variants"`
-	StashValues stash.StashValues `json:"stashValues"`
+	Template     string            `json:"template"`
+	Uri          string            `json:"uri"`
+	Method       string            `json:"method"`
+	Data         requestData       `json:"data"`
+	FormData     url.Values        `json:"formData"`
+	Multipart    MultipartFields   `json:"multipart"`
+	Query        url.Values        `json:"query"`
+	PathParams   map[string]string `json:"pathParams"`
+	Headers      http.Header       `json:"headers"`
+	Compress     string            `json:"compress"`
+	Auth         *auth.Auth        `json:"auth"`
+	Sign         *signer.Config    `json:"sign"`
+	GraphQL      *GraphQL          `json:"graphql"`
+	GRPC         *GRPC             `json:"grpc"`
+	Subprotocols []string          `json:"subprotocols"`
+	Stream       bool              `json:"stream"`
+	Protocol     string            `json:"protocol"`
+	Transport    *Transport        `json:"transport"`
+	Proxy        *Proxy            `json:"proxy"`
+	Timeout      string            `json:"timeout"`
+	Assertions   Assertions        `json:"assertions"`
+	Variants     Variants          `json:"variants"`
+	StashValues  stash.StashValues `json:"stashValues"`
+	Prompts      prompt.Prompts    `json:"prompts"`
 }
 
 // UnmarshalJSON will ensure that the request headers that are by default passed
@@ -69,6 +123,10 @@ func (rs *RequestSpec) UnmarshalJSON(j []byte) error {
 			Name  string `json:"name"`
 			Value string `json:"value"`
 		} `json:"formData"`
+		Query []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"query"`
 		*Alias
 	}{
 		Alias: (*Alias)(rs),
@@ -92,15 +150,53 @@ func (rs *RequestSpec) UnmarshalJSON(j []byte) error {
 	}
 
 	rs.FormData = f
+
+	q := url.Values{}
+	for _, qobj := range aux.Query {
+		q.Add(qobj.Name, qobj.Value)
+	}
+
+	rs.Query = q
 	return nil
 }
 
+const (
+	// KindRequest is the kind of a plain HTTP request resource.
+	KindRequest = "request"
+
+	// KindGraphQL is the kind of a GraphQL request resource. These are made in
+	// the same way as plain requests, with the body being built from the query.
+	KindGraphQL = "graphql"
+
+	// KindWebSocket is the kind of a WebSocket resource. Rather than being
+	// made, a connection is opened, with the variants being the messages that
+	// can be sent over it.
+	KindWebSocket = "websocket"
+
+	// KindGRPC is the kind of a gRPC request resource. These are made in the
+	// same way as plain requests, with the body being the encoded message and
+	// the response being decoded as JSON.
+	KindGRPC = "grpc"
+
+	// KindTemplate is the kind of a template resource, which is never made.
+	// Instead, it holds defaults that requests using it inherit.
+	KindTemplate = "template"
+)
+
 // Request represents a single resource which in itself can represent multiple
 // types of requests for said resource, by creating RequestVariants.
 type Request struct {
 	Kind string      `json:"kind"`
 	Name string      `json:"name"`
 	Spec RequestSpec `json:"spec"`
+
+	// Progress is called as the request body is being sent, if it is set.
+	Progress ProgressFunc `json:"-"`
+
+	// Scope is what the request is made with, such as the values within the
+	// stash. An empty scope is used if it is not set, such that nothing is
+	// shared with any other request.
+	Scope *Scope `json:"-"`
 }
 
 // Variant checks for and returns a variant given by it's name.
@@ -119,6 +215,192 @@ func (r Request) Variants() Variants {
 	return r.Spec.Variants
 }
 
+// Clone returns a deep copy of the request. Making a request modifies the spec
+// as variables are replaced, so a clone should be made when the original
+// resource needs to remain as it was loaded.
+func (r Request) Clone() Request {
+	c := r
+	c.Spec.Headers = cloneHeader(r.Spec.Headers)
+	c.Spec.FormData = cloneValues(r.Spec.FormData)
+	c.Spec.Multipart = append(MultipartFields(nil), r.Spec.Multipart...)
+	c.Spec.Query = cloneValues(r.Spec.Query)
+	c.Spec.PathParams = mergePathParams(r.Spec.PathParams, nil)
+	c.Spec.StashValues = append(stash.StashValues(nil), r.Spec.StashValues...)
+	c.Spec.Prompts = append(prompt.Prompts(nil), r.Spec.Prompts...)
+	c.Spec.Auth = cloneAuth(r.Spec.Auth)
+	c.Spec.Sign = cloneSigner(r.Spec.Sign)
+	c.Spec.GraphQL = r.Spec.GraphQL.clone()
+	c.Spec.GRPC = r.Spec.GRPC.clone()
+	c.Spec.Subprotocols = append([]string(nil), r.Spec.Subprotocols...)
+	c.Spec.Transport = r.Spec.Transport.clone()
+	c.Spec.Proxy = r.Spec.Proxy.clone()
+	c.Spec.Assertions = append(Assertions(nil), r.Spec.Assertions...)
+
+	c.Spec.Variants = nil
+	for _, v := range r.Spec.Variants {
+		c.Spec.Variants = append(c.Spec.Variants, v.Clone())
+	}
+	return c
+}
+
+// Prompts returns each prompt that is referenced by the request, and by the
+// given variant if there is one.
+func (r Request) Prompts(v *Variant) prompt.Prompts {
+	var ps prompt.Prompts
+	var defaults []string
+	pd := r.scope().Defaults
+	if a := pd.Auth; a != nil {
+		defaults = append(defaults, a.Strings()...)
+	}
+	if sc := pd.Sign; sc != nil {
+		defaults = append(defaults, sc.Strings()...)
+	}
+	if t := pd.Transport; t != nil {
+		defaults = append(defaults, t.strings()...)
+	}
+	if p := pd.Proxy; p != nil {
+		defaults = append(defaults, p.strings()...)
+	}
+	for _, s := range defaults {
+		for _, n := range prompt.Names(s) {
+			ps = appendPrompt(ps, r.Spec.Prompts.Get(n))
+		}
+	}
+	for _, s := range r.Spec.strings() {
+		for _, n := range prompt.Names(s) {
+			ps = appendPrompt(ps, r.Spec.Prompts.Get(n))
+		}
+	}
+	if v == nil {
+		return ps
+	}
+	for _, s := range v.strings() {
+		for _, n := range prompt.Names(s) {
+			ps = appendPrompt(ps, r.Spec.Prompts.Get(n))
+		}
+	}
+	return ps
+}
+
+// appendPrompt appends the prompt to the given prompts, unless one with the
+// same name already exists.
+func appendPrompt(ps prompt.Prompts, p prompt.Prompt) prompt.Prompts {
+	for _, e := range ps {
+		if e.Name == p.Name {
+			return ps
+		}
+	}
+	return append(ps, p)
+}
+
+// Auth returns the auth that will be used for the request, and the given
+// variant if there is one. The variant's auth is used over the request's,
+// which is used over the project's. A copy is returned, so that replacing any
+// variables within it leaves the original as it was loaded.
+func (r Request) Auth(v *Variant) *auth.Auth {
+	if v != nil && v.Auth != nil {
+		return cloneAuth(v.Auth)
+	}
+	if r.Spec.Auth != nil {
+		return cloneAuth(r.Spec.Auth)
+	}
+	return cloneAuth(r.scope().Defaults.Auth)
+}
+
+// cloneAuth returns a copy of the given auth.
+func cloneAuth(a *auth.Auth) *auth.Auth {
+	if a == nil {
+		return nil
+	}
+	c := *a
+	return &c
+}
+
+// Signer returns the signer config that will be used for the request, and the
+// given variant if there is one, in the same way as Auth.
+func (r Request) Signer(v *Variant) *signer.Config {
+	if v != nil && v.Sign != nil {
+		return cloneSigner(v.Sign)
+	}
+	if r.Spec.Sign != nil {
+		return cloneSigner(r.Spec.Sign)
+	}
+	return cloneSigner(r.scope().Defaults.Sign)
+}
+
+// cloneSigner returns a copy of the given signer config.
+func cloneSigner(sc *signer.Config) *signer.Config {
+	if sc == nil {
+		return nil
+	}
+	c := *sc
+	return &c
+}
+
+// strings returns each string within the request spec that may contain
+// variables, not including those of the variants.
+func (rs RequestSpec) strings() []string {
+	ss := []string{rs.Uri, rs.Method, rs.Data.String()}
+	for _, uv := range rs.FormData {
+		ss = append(ss, uv...)
+	}
+	for _, mf := range rs.Multipart {
+		ss = append(ss, mf.Value, mf.File, mf.Filename)
+	}
+	for _, qv := range rs.Query {
+		ss = append(ss, qv...)
+	}
+	for _, pv := range rs.PathParams {
+		ss = append(ss, pv)
+	}
+	for _, hv := range rs.Headers {
+		ss = append(ss, hv...)
+	}
+	if rs.Auth != nil {
+		ss = append(ss, rs.Auth.Strings()...)
+	}
+	if rs.Sign != nil {
+		ss = append(ss, rs.Sign.Strings()...)
+	}
+	if rs.GraphQL != nil {
+		ss = append(ss, rs.GraphQL.strings()...)
+	}
+	if rs.GRPC != nil {
+		ss = append(ss, rs.GRPC.strings()...)
+	}
+	if rs.Transport != nil {
+		ss = append(ss, rs.Transport.strings()...)
+	}
+	if rs.Proxy != nil {
+		ss = append(ss, rs.Proxy.strings()...)
+	}
+	return ss
+}
+
+// cloneHeader returns a copy of the given headers.
+func cloneHeader(h http.Header) http.Header {
+	if h == nil {
+		return nil
+	}
+	c := http.Header{}
+	for k, v := range h {
+		c[k] = append([]string(nil), v...)
+	}
+	return c
+}
+
+// cloneValues returns a copy of the given url values.
+func cloneValues(uv url.Values) url.Values {
+	if uv == nil {
+		return nil
+	}
+	c := url.Values{}
+	for k, v := range uv {
+		c[k] = append([]string(nil), v...)
+	}
+	return c
+}
+
 // httpRequest is an internal struct to store information about a spesefic
 // request that will be made, regardless if there is a variant or not.
 type httpRequest struct {
@@ -127,7 +409,26 @@ type httpRequest struct {
 	headers     http.Header
 	data        requestData
 	formData    url.Values
+	multipart   MultipartFields
+	query       url.Values
 	stashValues stash.StashValues
+	compress    string
+	auth        *auth.Auth
+	sign        *signer.Config
+	graphql     *GraphQL
+	grpc        *GRPC
+	stream      bool
+	protocol    string
+	dial        *Transport
+	proxy       *Proxy
+	timeout     string
+	assertions  Assertions
+	progress    ProgressFunc
+
+	// fs is the file system that files sent as the body are read from, with
+	// stash being where stash values found within the response are stored.
+	fs    fs.FS
+	stash *stash.Map
 }
 
 // Make makes a single HTTP request without a variant. Only the fields that come
@@ -136,15 +437,43 @@ func (r *Request) Make(baseURL url.URL) (*http.Response, RequestStat, error) {
 	// We updatet the request spec here before making a request to make sure it
 	// has all needed data, both read from files and parsed from variables
 	// contained within the config.
-	r.Spec.Update()
+	s := r.scope()
+	r.Spec.UpdateWith(s)
+
+	uri, err := expandPath(r.Spec.Uri, r.Spec.PathParams)
+	if err != nil {
+		return &http.Response{},
+			RequestStat{},
+			fmt.Errorf("Could not construct request: %s", err)
+	}
 
 	hr := httpRequest{
-		url:         fmt.Sprintf("%s%s", baseURL.String(), r.Spec.Uri),
+		url:         fmt.Sprintf("%s%s", baseURL.String(), uri),
 		method:      r.Spec.Method,
 		headers:     r.Spec.Headers,
 		data:        r.Spec.Data,
 		formData:    r.Spec.FormData,
+		multipart:   r.Spec.Multipart,
+		query:       r.Spec.Query,
 		stashValues: r.Spec.StashValues,
+		compress:    r.Spec.Compress,
+		auth:        s.parseAuth(r.Auth(nil)),
+		sign:        s.parseSigner(r.Signer(nil)),
+		graphql:     r.Spec.GraphQL,
+		grpc:        r.GRPC(nil),
+		stream:      r.Spec.Stream,
+		protocol:    r.Protocol(),
+		dial:        s.parseTransport(r.Transport()),
+		proxy:       s.parseProxy(r.Proxy()),
+		timeout:     r.Spec.Timeout,
+		assertions:  r.Spec.Assertions,
+		progress:    r.Progress,
+		fs:          s.FS,
+		stash:       s.Stash,
+	}
+	if hr.grpc != nil {
+		// gRPC methods are always called at the path of the method.
+		hr.url = baseURL.String() + hr.grpc.Path()
 	}
 	return hr.make()
 }
@@ -153,50 +482,156 @@ func (r *Request) Make(baseURL url.URL) (*http.Response, RequestStat, error) {
 // resource.
 func (r *Request) MakeWithVariant(
 	baseURL url.URL, v *Variant) (*http.Response, RequestStat, error) {
-	r.Spec.Update()
+	s := r.scope()
+	r.Spec.UpdateWith(s)
+	v.UpdateWith(s)
+
+	// The method, body, stash values and assertions are inherited from the
+	// request when the variant does not set them.
+	*v = v.Inherit(r.Spec.inherited())
 
 	// The variant headers need the base request headers added to it before the
 	// request is made.
 	v.Headers = headers.Concat(r.Spec.Headers, v.Headers)
+
+	// The same goes for query parameters, where those set by the variant take
+	// precedence over the ones set by the request.
+	v.Query = mergeQuery(r.Spec.Query, v.Query)
+
+	// Path parameters are also merged, so that the uri of the request and the
+	// path of the variant can be expanded together.
+	v.PathParams = mergePathParams(r.Spec.PathParams, v.PathParams)
+	path, err := expandPath(r.Spec.Uri+v.Path, v.PathParams)
+	if err != nil {
+		return &http.Response{},
+			RequestStat{},
+			fmt.Errorf("Could not construct request: %s", err)
+	}
+
 	hr := httpRequest{
-		url: fmt.Sprintf(
-			"%s%s%s", baseURL.String(), r.Spec.Uri, v.Path),
+		url:         fmt.Sprintf("%s%s", baseURL.String(), path),
 		method:      v.Method,
 		headers:     v.Headers,
 		data:        v.Data,
 		formData:    v.FormData,
+		multipart:   v.Multipart,
+		query:       v.Query,
 		stashValues: v.StashValues,
+		compress:    r.Spec.Compress,
+		auth:        s.parseAuth(r.Auth(v)),
+		sign:        s.parseSigner(r.Signer(v)),
+		graphql:     r.GraphQL(v),
+		grpc:        r.GRPC(v),
+		stream:      r.Spec.Stream,
+		protocol:    r.Protocol(),
+		dial:        s.parseTransport(r.Transport()),
+		proxy:       s.parseProxy(r.Proxy()),
+		timeout:     r.Spec.Timeout,
+		assertions:  v.Assertions,
+		progress:    r.Progress,
+		fs:          s.FS,
+		stash:       s.Stash,
+	}
+	if hr.grpc != nil {
+		hr.url = baseURL.String() + hr.grpc.Path()
 	}
 	return hr.make()
 }
 
+// RequestStat represents the stats recorded for a request that has been made.
 type RequestStat struct {
 	Total int
+
+	// Request and Response are the sizes of the request and response bodies.
+	Request  *BodySize
+	Response *BodySize
+
+	// Signature is the canonical string that was signed, if the request was
+	// signed, which is useful to see when the signature is rejected.
+	Signature string
+
+	// Assertions are the results of checking the assertions of the request
+	// against the response.
+	Assertions []AssertionResult
 }
 
 // make makes a request for either a standalone request, or a request with a
 // variant. It doesn't care about which one, as long as the url, request method
 // and headers are all passed through.
 func (hr httpRequest) make() (*http.Response, RequestStat, error) {
-	client := &http.Client{}
+	t, err := hr.transport()
+	if err != nil {
+		return &http.Response{},
+			RequestStat{},
+			fmt.Errorf("Could not construct request: %s", err)
+	}
+	client := &http.Client{Transport: t}
+	if hr.auth != nil {
+		hr.auth.TokenClient = hr.tokenClient
+	}
 
-	// Prepare the request
-	req, err := http.NewRequest(
-		hr.method, hr.url, strings.NewReader(hr.requestBody()))
+	// The timeout covers reading the body too, so it is not used for streams,
+	// which are read for as long as they are open.
+	if hr.timeout != "" && !hr.stream {
+		if client.Timeout, err = time.ParseDuration(hr.timeout); err != nil {
+			return &http.Response{},
+				RequestStat{},
+				fmt.Errorf("Could not construct request: invalid timeout: %s", err)
+		}
+	}
+
+	var method protoreflect.MethodDescriptor
+	if hr.grpc != nil {
+		if hr, method, err = hr.grpcRequest(client); err != nil {
+			return &http.Response{},
+				RequestStat{},
+				fmt.Errorf("Could not construct request: %s", err)
+		}
+	}
 
+	req, b, err := hr.newRequest()
 	if err != nil {
 		return &http.Response{},
 			RequestStat{},
 			fmt.Errorf("Could not construct request: %s", err)
 	}
 
-	req.Header = hr.headers
+	signature, err := hr.signRequest(req, b)
+	if err != nil {
+		return &http.Response{}, RequestStat{},
+			fmt.Errorf("Could not sign request: %s", err)
+	}
 
 	// Get the start time jsut before making the request
 	start := time.Now()
 
 	resp, err := client.Do(req)
 
+	// Some auth, such as digest, needs to answer a challenge sent back by the
+	// server, in which case the request is built and sent again.
+	if err == nil && hr.auth != nil && hr.auth.Challenged(resp) {
+		ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if req, b, err = hr.newRequest(); err != nil {
+			return &http.Response{},
+				RequestStat{},
+				fmt.Errorf("Could not construct request: %s", err)
+		}
+		if err := hr.auth.Answer(req, resp); err != nil {
+			if c, ok := b.reader.(io.Closer); ok {
+				c.Close()
+			}
+			return &http.Response{}, RequestStat{},
+				fmt.Errorf("Could not authenticate request: %s", err)
+		}
+		if signature, err = hr.signRequest(req, b); err != nil {
+			return &http.Response{}, RequestStat{},
+				fmt.Errorf("Could not sign request: %s", err)
+		}
+		resp, err = client.Do(req)
+	}
+
 	// Record the end time, even before catching any errors
 	end := time.Now().Sub(start)
 
@@ -206,10 +641,78 @@ func (hr httpRequest) make() (*http.Response, RequestStat, error) {
 	}
 
 	rs := RequestStat{
-		Total: int(end / time.Millisecond),
+		Total:     int(end / time.Millisecond),
+		Request:   b.size,
+		Response:  &BodySize{},
+		Signature: signature,
 	}
 
-	return hr.applyStash(resp), rs, nil
+	decodeResponse(resp, rs.Response)
+
+	if method != nil {
+		if err := decodeGRPCResponse(resp, method); err != nil {
+			return &http.Response{}, RequestStat{},
+				fmt.Errorf("Could not decode response: %s", err)
+		}
+	}
+
+	// Streamed responses are read as they arrive, so the body can not be read
+	// up front to find any stash values.
+	if hr.stream || IsStream(resp) {
+		return resp, rs, nil
+	}
+	rs.Assertions = hr.assertions.Check(resp)
+	if err := hr.applyStash(resp); err != nil {
+		return resp, rs, err
+	}
+	return resp, rs, nil
+}
+
+// newRequest builds the request to be sent, with its body, headers and auth.
+// The headers are copied so that those set here, such as any credentials, are
+// not added to the request spec that they came from.
+func (hr httpRequest) newRequest() (*http.Request, body, error) {
+	u, err := hr.fullURL()
+	if err != nil {
+		return nil, body{}, err
+	}
+
+	b, err := hr.body()
+	if err != nil {
+		return nil, body{}, err
+	}
+
+	req, err := http.NewRequest(hr.method, u, b.reader)
+	if err != nil {
+		return nil, body{}, err
+	}
+
+	req.Header = cloneHeader(hr.headers)
+	if req.Header == nil {
+		req.Header = http.Header{}
+	}
+	if b.contentType != "" {
+		req.Header.Set(headers.ContentType, b.contentType)
+	}
+	req.ContentLength = b.length
+
+	if hr.compress != "" && b.length != 0 {
+		req.Header.Set("Content-Encoding", hr.compress)
+	}
+
+	// The transport will only decode responses when it sets Accept-Encoding
+	// itself, so it is set here instead, as the transport would, to make sure
+	// responses are always decoded in the same way.
+	if req.Header.Get(headers.AcceptEncoding) == "" {
+		req.Header.Set(headers.AcceptEncoding, encodingGzip)
+	}
+
+	if hr.auth != nil {
+		if err := hr.auth.Apply(req); err != nil {
+			return nil, body{}, err
+		}
+	}
+	return req, b, nil
 }
 
 // requestBody checks to see if there is any form data present. If so, an
@@ -225,9 +728,12 @@ func (hr httpRequest) requestBody() string {
 // applyStash looks as the response body from the given request, checks to see
 // if it can find the needed JSON path from the stash values, and store that in
 // memory ready to be used within another request.
-func (hr httpRequest) applyStash(r *http.Response) *http.Response {
+func (hr httpRequest) applyStash(r *http.Response) error {
 	if len(hr.stashValues) == 0 {
-		return r
+		return nil
+	}
+	if hr.stash == nil {
+		return fmt.Errorf("Could not stash values: there is no stash to keep them in.")
 	}
 
 	rb, _ := ioutil.ReadAll(r.Body)
@@ -236,8 +742,8 @@ func (hr httpRequest) applyStash(r *http.Response) *http.Response {
 		nsv, _, _, _ := jsonparser.Get(rb, hr.stashValues[i].JsonPath...)
 		hr.stashValues[i].Value = string(nsv)
 	}
-	hr.stashValues.Push()
-	return r
+	hr.stashValues.PushTo(hr.stash)
+	return nil
 }
 
 // Requests represents multiple Request resources.
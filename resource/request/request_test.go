@@ -152,6 +152,48 @@ func TestMakeWithVariant(t *testing.T) {
 	assert.Equal(t, `{"error": false}`, string(b), "JSON encoded, error : false")
 }
 
+func TestMakeWithVariantInherits(t *testing.T) {
+	teardown := setup()
+	defer teardown()
+
+	r := Request{
+		Kind: "request",
+		Name: "test-request",
+		Spec: RequestSpec{
+			Uri:     "/inherit",
+			Method:  "POST",
+			Data:    requestData{contents: []byte(`{"name": "test"}`)},
+			Headers: http.Header{},
+			Variants: Variants{
+				Variant{
+					Name:    "test-variant",
+					Headers: http.Header{"X-Test": []string{"yes"}},
+				},
+			},
+		},
+	}
+
+	mux.HandleFunc("/inherit", func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		fmt.Fprintf(w, "%s %s %s", r.Method, r.Header.Get("X-Test"), b)
+	})
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	resp, _, err := r.MakeWithVariant(*u, &r.Spec.Variants[0])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	b, _ := ioutil.ReadAll(resp.Body)
+
+	assert.Equal(t, `POST yes {"name": "test"}`, string(b),
+		"the method and body should be inherited from the request")
+}
+
 func TestUMake(t *testing.T) {
 	teardown := setup()
 	defer teardown()
@@ -228,9 +270,34 @@ func TestRequestDataString(t *testing.T) {
 }
 
 func TestRequestDataLoadConents(t *testing.T) {
-	// Messy!
-	utils.ProjectPath = "../../"
 	rd := requestData{FromFile: "projects/test_project/data/test.json"}
-	rd.loadContents()
+	rd.loadContents(utils.DirFS("../../"))
 	assert.Equal(t, `{"error": "false"}`, string(rd.contents))
 }
+
+func TestApplyStashWithoutStash(t *testing.T) {
+	teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, fixture())
+	})
+
+	hr := httpRequest{
+		url:     server.URL,
+		method:  "GET",
+		headers: http.Header{},
+		stashValues: stash.StashValues{
+			stash.StashValue{Name: "error", JsonPath: []string{"error"}},
+		},
+	}
+	_, _, err := hr.make()
+	assert.NotNil(t, err)
+
+	hr.stash = &stash.Map{}
+	_, _, err = hr.make()
+	assert.Nil(t, err)
+	sv, _ := hr.stash.Get("error")
+	assert.Equal(t, "false", sv.Value)
+}
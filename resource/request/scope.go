@@ -0,0 +1,109 @@
+package request
+
+import (
+	"io/fs"
+
+	"github.com/hazbo/httpu/env"
+	"github.com/hazbo/httpu/prompt"
+	"github.com/hazbo/httpu/resource/request/auth"
+	"github.com/hazbo/httpu/resource/request/signer"
+	"github.com/hazbo/httpu/stash"
+)
+
+// Scope holds everything that requests are made with besides the requests
+// themselves: the file system that data files are read from, the defaults of
+// the project, the values that variables are replaced with and the proxy that
+// overrides any other. Each project being used has its own, so that requests of
+// different projects can be made at the same time without sharing anything.
+type Scope struct {
+	FS            fs.FS
+	Defaults      Defaults
+	Stash         *stash.Map
+	Prompts       *prompt.Map
+	Env           *env.Map
+	ProxyOverride *ProxyOverride
+}
+
+// NewScope returns a scope for the project within the given file system, with
+// nothing stashed or entered for any prompt. No files can be read from a scope
+// without a file system.
+func NewScope(fsys fs.FS) *Scope {
+	if fsys == nil {
+		fsys = noFiles{}
+	}
+	return &Scope{
+		FS:            fsys,
+		Stash:         &stash.Map{},
+		Prompts:       &prompt.Map{},
+		Env:           &env.Map{},
+		ProxyOverride: &ProxyOverride{},
+	}
+}
+
+// DefaultScope returns the scope of requests that were not loaded with one,
+// which is an empty scope without any files, defaults or values of its own.
+func DefaultScope() *Scope {
+	return NewScope(nil)
+}
+
+// noFiles is the file system of a scope without one, within which no files
+// exist.
+type noFiles struct{}
+
+// Open is an implementation of fs.FS.
+func (noFiles) Open(name string) (fs.File, error) {
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// scope returns the scope that the request is made with, which is the default
+// scope unless the request was loaded with its own.
+func (r Request) scope() *Scope {
+	if r.Scope != nil {
+		return r.Scope
+	}
+	return DefaultScope()
+}
+
+// parse replaces any environment, stash and prompt variables within the given
+// string, in that order.
+func (s *Scope) parse(v string) string {
+	return s.Prompts.Parse(s.Stash.Parse(s.Env.Parse(v)))
+}
+
+// parseAuth replaces any variables within the given auth, which also caches
+// any tokens it obtains within the stash of the scope.
+func (s *Scope) parseAuth(a *auth.Auth) *auth.Auth {
+	if a == nil {
+		return nil
+	}
+	a.Parse(s.parse)
+	a.Stash = s.Stash
+	return a
+}
+
+// parseSigner replaces any variables within the given signer config.
+func (s *Scope) parseSigner(sc *signer.Config) *signer.Config {
+	if sc == nil {
+		return nil
+	}
+	sc.Parse(s.parse)
+	return sc
+}
+
+// parseTransport replaces any variables within the given transport.
+func (s *Scope) parseTransport(t *Transport) *Transport {
+	if t == nil {
+		return nil
+	}
+	t.parse(s.parse)
+	return t
+}
+
+// parseProxy replaces any variables within the given proxy.
+func (s *Scope) parseProxy(p *Proxy) *Proxy {
+	if p == nil {
+		return nil
+	}
+	p.parse(s.parse)
+	return p
+}
@@ -0,0 +1,47 @@
+package request
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/hazbo/httpu/resource/request/signer"
+)
+
+// signRequest signs the request using the configured signer, if there is one,
+// returning the canonical string that was signed. Signers need the whole body,
+// so it is read into memory first, meaning that signed bodies are not
+// streamed.
+func (hr httpRequest) signRequest(req *http.Request, b body) (string, error) {
+	if hr.sign == nil {
+		return "", nil
+	}
+
+	s, err := signer.New(*hr.sign)
+	if err != nil {
+		return "", err
+	}
+
+	var data []byte
+	if b.reader != nil {
+		data, err = ioutil.ReadAll(b.reader)
+		if c, ok := b.reader.(io.Closer); ok {
+			c.Close()
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+
+	req.Body = ioutil.NopCloser(bytes.NewReader(data))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(data)), nil
+	}
+	req.ContentLength = int64(len(data))
+	if len(data) == 0 {
+		req.Body = http.NoBody
+	}
+
+	return s.Sign(req, data)
+}
@@ -0,0 +1,39 @@
+package request
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/hazbo/httpu/resource/request/signer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMakeWithSigner(t *testing.T) {
+	teardown := setup()
+	defer teardown()
+
+	var got string
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusForbidden)
+	})
+
+	r := Request{
+		Spec: RequestSpec{
+			Uri:     "/",
+			Method:  "POST",
+			Headers: http.Header{},
+			Data:    requestData{contents: []byte(`{"name":"test"}`)},
+			Sign:    &signer.Config{Type: signer.HMAC, Secret: "secret"},
+		},
+	}
+
+	u, _ := url.Parse(server.URL)
+	resp, stat, err := r.Make(*u)
+	assert.Nil(t, err)
+	resp.Body.Close()
+
+	assert.Contains(t, got, "HMAC ")
+	assert.Contains(t, stat.Signature, "POST\n/\n")
+}
@@ -0,0 +1,170 @@
+package signer
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// AWSV4 is the type of the AWS Signature Version 4 signer.
+const AWSV4 = "awsV4"
+
+const (
+	awsAlgorithm  = "AWS4-HMAC-SHA256"
+	awsDateFormat = "20060102T150405Z"
+)
+
+// awsV4 signs requests using AWS Signature Version 4, as used by API Gateway
+// and most other AWS services.
+type awsV4 struct {
+	Config
+}
+
+func newAWSV4(c Config) (Signer, error) {
+	if c.AccessKey == "" || c.SecretKey == "" {
+		return nil, fmt.Errorf("AWS signing requires an accessKey and secretKey.")
+	}
+	if c.Region == "" || c.Service == "" {
+		return nil, fmt.Errorf("AWS signing requires a region and service.")
+	}
+	return awsV4{c}, nil
+}
+
+// Sign is an implementation of Signer.
+func (s awsV4) Sign(req *http.Request, body []byte) (string, error) {
+	t := now().UTC()
+	amzDate := t.Format(awsDateFormat)
+	date := amzDate[:8]
+
+	payload := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if s.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.SessionToken)
+	}
+	if s.Service == "s3" {
+		req.Header.Set("X-Amz-Content-Sha256", payload)
+	}
+
+	signed, canonicalHeaders := awsCanonicalHeaders(req)
+
+	canonical := strings.Join([]string{
+		req.Method,
+		awsCanonicalPath(req.URL, s.Service != "s3"),
+		awsCanonicalQuery(req.URL),
+		canonicalHeaders,
+		signed,
+		payload,
+	}, "\n")
+
+	scope := strings.Join([]string{date, s.Region, s.Service, "aws4_request"}, "/")
+	toSign := strings.Join([]string{
+		awsAlgorithm, amzDate, scope, sha256Hex([]byte(canonical))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.SecretKey), date)
+	key = hmacSHA256(key, s.Region)
+	key = hmacSHA256(key, s.Service)
+	key = hmacSHA256(key, "aws4_request")
+	sig := hex.EncodeToString(hmacSHA256(key, toSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		awsAlgorithm, s.AccessKey, scope, signed, sig))
+
+	return canonical + "\n\n" + toSign, nil
+}
+
+// awsCanonicalPath returns the path of the url, encoded as AWS expects. All
+// services other than S3 expect each segment to be encoded twice.
+func awsCanonicalPath(u *url.URL, double bool) string {
+	p := u.EscapedPath()
+	if p == "" {
+		return "/"
+	}
+	if !double {
+		return p
+	}
+	segs := strings.Split(p, "/")
+	for i, s := range segs {
+		segs[i] = awsEscape(s)
+	}
+	return strings.Join(segs, "/")
+}
+
+// awsCanonicalQuery returns the query of the url sorted by key and then value,
+// with each being encoded as AWS expects.
+func awsCanonicalQuery(u *url.URL) string {
+	var qs []string
+	for k, vs := range u.Query() {
+		for _, v := range vs {
+			qs = append(qs, awsEscape(k)+"="+awsEscape(v))
+		}
+	}
+	sort.Strings(qs)
+	return strings.Join(qs, "&")
+}
+
+// awsCanonicalHeaders returns the names of the signed headers and the
+// canonical form of them. The host, content type and any X-Amz headers are
+// signed.
+func awsCanonicalHeaders(req *http.Request) (string, string) {
+	hs := map[string]string{"host": req.URL.Host}
+	if req.Host != "" {
+		hs["host"] = req.Host
+	}
+	for k, vs := range req.Header {
+		lk := strings.ToLower(k)
+		if lk != "content-type" && !strings.HasPrefix(lk, "x-amz-") {
+			continue
+		}
+		tvs := make([]string, len(vs))
+		for i, v := range vs {
+			tvs[i] = strings.Join(strings.Fields(v), " ")
+		}
+		hs[lk] = strings.Join(tvs, ",")
+	}
+
+	names := make([]string, 0, len(hs))
+	for k := range hs {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, n := range names {
+		fmt.Fprintf(&b, "%s:%s\n", n, hs[n])
+	}
+	return strings.Join(names, ";"), b.String()
+}
+
+// awsEscape encodes everything except unreserved characters, as described by
+// RFC 3986.
+func awsEscape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+func sha256Hex(b []byte) string {
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+func hmacSHA256(key []byte, s string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(s))
+	return h.Sum(nil)
+}
@@ -0,0 +1,112 @@
+package signer
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// HMAC is the type of the generic HMAC-SHA256 signer.
+const HMAC = "hmac"
+
+const (
+	componentMethod = "method"
+	componentPath   = "path"
+	componentDate   = "date"
+	componentDigest = "digest"
+
+	// componentHeader is the prefix of a component that is taken from the
+	// value of a header, such as "header:X-Request-Id".
+	componentHeader = "header:"
+)
+
+// defaultComponents are the parts of a request that are signed, unless others
+// are configured.
+var defaultComponents = []string{
+	componentMethod, componentPath, componentDate, componentDigest}
+
+// hmacSigner signs a string made up of the configured components of a
+// request, each on its own line, using HMAC-SHA256. The signature is sent in
+// the configured header, which defaults to Authorization, as:
+//
+//	<prefix><keyId>:<signature>
+//
+// with the prefix defaulting to "HMAC " and the key ID being left out if it
+// is not set.
+type hmacSigner struct {
+	Config
+}
+
+func newHMAC(c Config) (Signer, error) {
+	if c.Secret == "" {
+		return nil, fmt.Errorf("HMAC signing requires a secret.")
+	}
+	switch c.Encoding {
+	case "", "base64", "hex":
+	default:
+		return nil, fmt.Errorf(
+			"HMAC signatures can be encoded as base64 or hex, not \"%s\".", c.Encoding)
+	}
+	if len(c.Components) == 0 {
+		c.Components = defaultComponents
+	}
+	for _, cp := range c.Components {
+		switch {
+		case cp == componentMethod, cp == componentPath, cp == componentDate,
+			cp == componentDigest, strings.HasPrefix(cp, componentHeader):
+		default:
+			return nil, fmt.Errorf("Unsupported HMAC component \"%s\".", cp)
+		}
+	}
+	return hmacSigner{c}, nil
+}
+
+// Sign is an implementation of Signer.
+func (s hmacSigner) Sign(req *http.Request, body []byte) (string, error) {
+	parts := make([]string, len(s.Components))
+	for i, cp := range s.Components {
+		switch {
+		case cp == componentMethod:
+			parts[i] = req.Method
+		case cp == componentPath:
+			parts[i] = req.URL.RequestURI()
+		case cp == componentDate:
+			if req.Header.Get("Date") == "" {
+				req.Header.Set("Date", now().UTC().Format(http.TimeFormat))
+			}
+			parts[i] = req.Header.Get("Date")
+		case cp == componentDigest:
+			h := sha256.Sum256(body)
+			req.Header.Set("Digest",
+				"SHA-256="+base64.StdEncoding.EncodeToString(h[:]))
+			parts[i] = req.Header.Get("Digest")
+		default:
+			parts[i] = req.Header.Get(strings.TrimPrefix(cp, componentHeader))
+		}
+	}
+	canonical := strings.Join(parts, "\n")
+
+	mac := hmacSHA256([]byte(s.Secret), canonical)
+	sig := base64.StdEncoding.EncodeToString(mac)
+	if s.Encoding == "hex" {
+		sig = hex.EncodeToString(mac)
+	}
+	if s.KeyID != "" {
+		sig = s.KeyID + ":" + sig
+	}
+
+	prefix := "HMAC "
+	if s.Prefix != nil {
+		prefix = *s.Prefix
+	}
+	header := s.Header
+	if header == "" {
+		header = "Authorization"
+	}
+	req.Header.Set(header, prefix+sig)
+
+	return canonical, nil
+}
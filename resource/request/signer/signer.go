@@ -0,0 +1,114 @@
+package signer
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// Signer signs a request once it has been built, after any variables have
+// been replaced. The body is passed separately as it has already been read
+// from the request. The canonical string that was signed is returned, so that
+// it can be shown when the server rejects the signature.
+type Signer interface {
+	Sign(req *http.Request, body []byte) (string, error)
+}
+
+// Factory creates a signer from its config.
+type Factory func(c Config) (Signer, error)
+
+// Config represents the settings for a signer, set using "sign" on a project,
+// request or variant. Fields that a signer does not use are ignored, with
+// Params being available to signers that need anything else.
+type Config struct {
+	Type string `json:"type"`
+
+	// AWS Signature Version 4.
+	AccessKey    string `json:"accessKey"`
+	SecretKey    string `json:"secretKey"`
+	SessionToken string `json:"sessionToken"`
+	Region       string `json:"region"`
+	Service      string `json:"service"`
+
+	// Generic HMAC-SHA256.
+	Secret     string   `json:"secret"`
+	KeyID      string   `json:"keyId"`
+	Header     string   `json:"header"`
+	Prefix     *string  `json:"prefix"`
+	Encoding   string   `json:"encoding"`
+	Components []string `json:"components"`
+
+	Params map[string]string `json:"params"`
+}
+
+// Strings returns each string within the config that may contain variables.
+func (c Config) Strings() []string {
+	ss := []string{c.AccessKey, c.SecretKey, c.SessionToken, c.Region,
+		c.Service, c.Secret, c.KeyID}
+	for _, p := range c.Params {
+		ss = append(ss, p)
+	}
+	return ss
+}
+
+// Parse replaces any variables within the config using the given parser.
+func (c *Config) Parse(parse func(string) string) {
+	c.AccessKey = parse(c.AccessKey)
+	c.SecretKey = parse(c.SecretKey)
+	c.SessionToken = parse(c.SessionToken)
+	c.Region = parse(c.Region)
+	c.Service = parse(c.Service)
+	c.Secret = parse(c.Secret)
+	c.KeyID = parse(c.KeyID)
+
+	if c.Params != nil {
+		ps := make(map[string]string, len(c.Params))
+		for k, v := range c.Params {
+			ps[k] = parse(v)
+		}
+		c.Params = ps
+	}
+}
+
+// String returns the signer used, as shown in the request view. Keys and
+// secrets are never included.
+func (c Config) String() string {
+	return c.Type
+}
+
+// factories holds each registered signer by type.
+var factories = map[string]Factory{}
+
+// Register makes a signer available to be used by the given type. Signers
+// registered with a type that already exists replace the existing one.
+func Register(typ string, f Factory) {
+	factories[typ] = f
+}
+
+// Types returns the type of each registered signer.
+func Types() []string {
+	ts := make([]string, 0, len(factories))
+	for t := range factories {
+		ts = append(ts, t)
+	}
+	sort.Strings(ts)
+	return ts
+}
+
+// New creates the signer for the given config.
+func New(c Config) (Signer, error) {
+	f, ok := factories[c.Type]
+	if !ok {
+		return nil, fmt.Errorf("Unsupported signer \"%s\".", c.Type)
+	}
+	return f(c)
+}
+
+// now returns the time used when signing requests, which is replaced in tests.
+var now = time.Now
+
+func init() {
+	Register(AWSV4, newAWSV4)
+	Register(HMAC, newHMAC)
+}
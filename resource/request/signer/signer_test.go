@@ -0,0 +1,88 @@
+package signer
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fixedNow() func() {
+	now = func() time.Time {
+		return time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+	}
+	return func() { now = time.Now }
+}
+
+// The expected signatures are taken from the AWS Signature Version 4 test
+// suite, using the get-vanilla and get-vanilla-query-order-key-case requests.
+func TestAWSV4(t *testing.T) {
+	defer fixedNow()()
+
+	s, err := New(Config{
+		Type:      AWSV4,
+		AccessKey: "AKIDEXAMPLE",
+		SecretKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		Region:    "us-east-1",
+		Service:   "service",
+	})
+	assert.Nil(t, err)
+
+	tests := []struct {
+		url       string
+		signature string
+	}{
+		{"https://example.amazonaws.com/",
+			"5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"},
+		{"https://example.amazonaws.com/?Param2=value2&Param1=value1",
+			"b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500"},
+	}
+
+	for _, tt := range tests {
+		req, _ := http.NewRequest("GET", tt.url, nil)
+		canonical, err := s.Sign(req, nil)
+		assert.Nil(t, err)
+
+		assert.True(t, strings.HasPrefix(canonical, "GET\n/\n"))
+		assert.Equal(t, "AWS4-HMAC-SHA256 "+
+			"Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, "+
+			"SignedHeaders=host;x-amz-date, Signature="+tt.signature,
+			req.Header.Get("Authorization"))
+	}
+}
+
+func TestHMAC(t *testing.T) {
+	defer fixedNow()()
+
+	s, err := New(Config{Type: HMAC, Secret: "secret", KeyID: "key"})
+	assert.Nil(t, err)
+
+	req, _ := http.NewRequest("POST", "http://localhost/items?a=1", nil)
+	canonical, err := s.Sign(req, []byte(`{"name":"test"}`))
+	assert.Nil(t, err)
+
+	assert.Equal(t, "POST\n/items?a=1\nSun, 30 Aug 2015 12:36:00 GMT\n"+
+		"SHA-256=fZ/SBR/DKzL+qxCUb6truRQmq345qlQ5KJ7YkoZKqR0=", canonical)
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(canonical))
+	assert.Equal(t,
+		"HMAC key:"+base64.StdEncoding.EncodeToString(mac.Sum(nil)),
+		req.Header.Get("Authorization"))
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(Config{Type: "unknown"})
+	assert.NotNil(t, err)
+
+	_, err = New(Config{Type: HMAC, Secret: "s", Components: []string{"body"}})
+	assert.NotNil(t, err)
+
+	_, err = New(Config{Type: AWSV4, AccessKey: "a", SecretKey: "s"})
+	assert.NotNil(t, err)
+}
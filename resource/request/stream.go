@@ -0,0 +1,129 @@
+package request
+
+import (
+	"bufio"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hazbo/httpu/resource/request/headers"
+)
+
+// streamContentTypes are the content types of responses that are shown as they
+// arrive, rather than once they have been read in full.
+var streamContentTypes = []string{
+	"text/event-stream",
+	"application/x-ndjson",
+	"application/ndjson",
+	"application/jsonl",
+	"application/stream+json",
+}
+
+// IsStream returns true if the response is a stream, such as server-sent
+// events or newline delimited JSON, going by its content type alone.
+func IsStream(resp *http.Response) bool {
+	mt, _, _ := mime.ParseMediaType(resp.Header.Get(headers.ContentType))
+	for _, ct := range streamContentTypes {
+		if mt == ct {
+			return true
+		}
+	}
+	return false
+}
+
+// IsEventStream returns true if the response is a stream of server-sent
+// events.
+func IsEventStream(resp *http.Response) bool {
+	mt, _, _ := mime.ParseMediaType(resp.Header.Get(headers.ContentType))
+	return mt == "text/event-stream"
+}
+
+// LastEventIDHeader is sent when reconnecting to an event stream, with the ID
+// of the last event that was received.
+const LastEventIDHeader = "Last-Event-ID"
+
+// DefaultRetry is how long to wait before reconnecting to an event stream,
+// unless the server has sent a retry field.
+const DefaultRetry = 3 * time.Second
+
+// Event represents a single server-sent event.
+type Event struct {
+	ID    string
+	Event string
+	Data  string
+}
+
+// EventReader reads server-sent events from a response body, as described by
+// the HTML specification. LastEventID and Retry are updated as events are
+// read, so that they can be used when reconnecting.
+type EventReader struct {
+	LastEventID string
+	Retry       time.Duration
+
+	r *bufio.Reader
+}
+
+// NewEventReader returns an EventReader that reads from r, carrying on from the
+// ID of the last event received, if there was one.
+func NewEventReader(r io.Reader, lastEventID string) *EventReader {
+	return &EventReader{
+		LastEventID: lastEventID,
+		Retry:       DefaultRetry,
+		r:           bufio.NewReader(r),
+	}
+}
+
+// Next returns the next event, blocking until it has been received in full.
+// Blocks that set no data, such as those setting only retry, are not returned
+// as events, and any event type they set is not kept for the next event.
+func (er *EventReader) Next() (Event, error) {
+	var (
+		ev   Event
+		data []string
+		seen bool
+	)
+	for {
+		l, err := er.r.ReadString('\n')
+		if err != nil && (err != io.EOF || l == "") {
+			return Event{}, err
+		}
+		l = strings.TrimRight(l, "\r\n")
+
+		if l == "" {
+			if !seen {
+				ev = Event{}
+				continue
+			}
+			ev.ID = er.LastEventID
+			ev.Data = strings.Join(data, "\n")
+			return ev, nil
+		}
+		if strings.HasPrefix(l, ":") {
+			continue
+		}
+
+		field, value := l, ""
+		if i := strings.IndexByte(l, ':'); i >= 0 {
+			field, value = l[:i], strings.TrimPrefix(l[i+1:], " ")
+		}
+
+		switch field {
+		case "event":
+			ev.Event = value
+		case "data":
+			data = append(data, value)
+			seen = true
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				er.LastEventID = value
+			}
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil && ms >= 0 {
+				er.Retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package request
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hazbo/httpu/stash"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventReader(t *testing.T) {
+	er := NewEventReader(strings.NewReader(
+		": comment\n"+
+			"retry: 1500\n\n"+
+			"event: update\nid: 1\ndata: {\"a\": 1}\n\n"+
+			"data: first\r\ndata: second\r\n\r\n"+
+			"id: 3\ndata\n\n"), "0")
+
+	ev, err := er.Next()
+	assert.Nil(t, err)
+	assert.Equal(t, Event{ID: "1", Event: "update", Data: `{"a": 1}`}, ev)
+	assert.Equal(t, 1500*time.Millisecond, er.Retry)
+
+	ev, err = er.Next()
+	assert.Nil(t, err)
+	assert.Equal(t, Event{ID: "1", Data: "first\nsecond"}, ev)
+
+	ev, err = er.Next()
+	assert.Nil(t, err)
+	assert.Equal(t, Event{ID: "3"}, ev)
+	assert.Equal(t, "3", er.LastEventID)
+
+	_, err = er.Next()
+	assert.Equal(t, io.EOF, err)
+
+	// The event type of a block without data is not kept.
+	er = NewEventReader(strings.NewReader("event: ping\n\ndata: x\n\n"), "")
+	ev, err = er.Next()
+	assert.Nil(t, err)
+	assert.Equal(t, Event{Data: "x"}, ev)
+}
+
+func TestMakeStream(t *testing.T) {
+	teardown := setup()
+	defer teardown()
+
+	// The response is not finished until the test has read the first event,
+	// so making the request must not read the body for stash values.
+	release := make(chan struct{})
+	var lastEventID string
+	mux.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
+		lastEventID = r.Header.Get(LastEventIDHeader)
+		w.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
+		io.WriteString(w, "id: 7\ndata: hello\n\n")
+		w.(http.Flusher).Flush()
+		<-release
+	})
+
+	r := Request{
+		Spec: RequestSpec{
+			Uri:     "/events",
+			Method:  "GET",
+			Headers: http.Header{LastEventIDHeader: []string{"6"}},
+			StashValues: stash.StashValues{
+				stash.StashValue{Name: "id", JsonPath: []string{"id"}},
+			},
+		},
+	}
+
+	u, _ := url.Parse(server.URL)
+	resp, _, err := r.Make(*u)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+	defer close(release)
+
+	assert.True(t, IsStream(resp))
+	assert.True(t, IsEventStream(resp))
+	assert.Equal(t, "6", lastEventID)
+
+	ev, err := NewEventReader(resp.Body, "").Next()
+	assert.Nil(t, err)
+	assert.Equal(t, Event{ID: "7", Data: "hello"}, ev)
+}
@@ -0,0 +1,85 @@
+package request
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hazbo/httpu/schema"
+)
+
+// TemplateError is an error with the template used by a request, such as it
+// not existing.
+type TemplateError struct {
+	Request string
+	Message string
+	Hint    string
+}
+
+// Error returns the error along with the request that it is for.
+func (e TemplateError) Error() string {
+	return fmt.Sprintf("Could not use template for request \"%s\": %s",
+		e.Request, e.Message)
+}
+
+// ApplyTemplates replaces the spec of each of the given requests that uses a
+// template with one that inherits the defaults of the template, in place. An
+// error is returned for each request that uses a template that does not exist.
+func ApplyTemplates(rm, templates map[string]Request) []TemplateError {
+	names := make([]string, 0, len(rm))
+	for n := range rm {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	var errs []TemplateError
+	for _, n := range names {
+		r := rm[n]
+		if r.Spec.Template == "" {
+			continue
+		}
+		t, ok := templates[r.Spec.Template]
+		if !ok {
+			known := make([]string, 0, len(templates))
+			for tn := range templates {
+				known = append(known, tn)
+			}
+			sort.Strings(known)
+			errs = append(errs, TemplateError{
+				Request: n,
+				Message: fmt.Sprintf("unknown template \"%s\"", r.Spec.Template),
+				Hint:    schema.Suggest(r.Spec.Template, known),
+			})
+			continue
+		}
+		r.Spec = r.Spec.withTemplate(t.Spec)
+		rm[n] = r
+	}
+	return errs
+}
+
+// withTemplate returns a copy of the request spec with the defaults of the
+// template added to it. Headers and query parameters are merged, with those of
+// the request replacing those of the template with the same name, and the
+// assertions of both are checked. The auth and timeout of the template are
+// only used when the request does not set its own.
+func (rs RequestSpec) withTemplate(t RequestSpec) RequestSpec {
+	if len(t.Headers) > 0 {
+		h := cloneHeader(t.Headers)
+		for k, hv := range rs.Headers {
+			h[k] = hv
+		}
+		rs.Headers = h
+	}
+	if len(t.Query) > 0 {
+		rs.Query = mergeQuery(t.Query, rs.Query)
+	}
+	if rs.Auth == nil {
+		rs.Auth = cloneAuth(t.Auth)
+	}
+	if rs.Timeout == "" {
+		rs.Timeout = t.Timeout
+	}
+	rs.Assertions = append(append(Assertions(nil), t.Assertions...),
+		rs.Assertions...)
+	return rs
+}
@@ -0,0 +1,60 @@
+package request
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/hazbo/httpu/resource/request/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyTemplates(t *testing.T) {
+	templates := map[string]Request{
+		"jsonApi": {Name: "jsonApi", Kind: KindTemplate, Spec: RequestSpec{
+			Headers: http.Header{
+				"Accept":       {"application/json"},
+				"Content-Type": {"application/json"},
+			},
+			Query:      url.Values{"format": {"json"}, "page": {"1"}},
+			Auth:       &auth.Auth{Type: auth.Bearer, Token: "abc"},
+			Timeout:    "10s",
+			Assertions: Assertions{{Status: 200}},
+		}},
+	}
+	rm := map[string]Request{
+		"users": {Name: "users", Spec: RequestSpec{
+			Template:   "jsonApi",
+			Uri:        "/users",
+			Headers:    http.Header{"Content-Type": {"text/plain"}},
+			Query:      url.Values{"page": {"2"}},
+			Timeout:    "1s",
+			Assertions: Assertions{{Header: "X-Id"}},
+		}},
+		"orders": {Name: "orders", Spec: RequestSpec{Template: "jsonapi"}},
+		"plain":  {Name: "plain", Spec: RequestSpec{Uri: "/"}},
+	}
+
+	errs := ApplyTemplates(rm, templates)
+	assert.Equal(t, []TemplateError{{
+		Request: "orders",
+		Message: `unknown template "jsonapi"`,
+		Hint:    `did you mean "jsonApi"?`,
+	}}, errs)
+
+	s := rm["users"].Spec
+	assert.Equal(t, http.Header{
+		"Accept":       {"application/json"},
+		"Content-Type": {"text/plain"},
+	}, s.Headers, "headers of the request should replace those of the template")
+	assert.Equal(t, url.Values{"format": {"json"}, "page": {"2"}}, s.Query)
+	assert.Equal(t, "abc", s.Auth.Token)
+	assert.Equal(t, "1s", s.Timeout)
+	assert.Equal(t, Assertions{{Status: 200}, {Header: "X-Id"}}, s.Assertions)
+
+	assert.Nil(t, rm["plain"].Spec.Headers, "requests without a template are kept")
+	assert.Equal(t, http.Header{
+		"Accept":       {"application/json"},
+		"Content-Type": {"application/json"},
+	}, templates["jsonApi"].Spec.Headers, "the template should not be changed")
+}
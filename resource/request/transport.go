@@ -0,0 +1,293 @@
+package request
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The protocols that a request can be made with. By default, HTTP/2 is used
+// when the server supports it over TLS, and HTTP/1.1 otherwise.
+const (
+	// ProtocolHTTP1 makes the request using HTTP/1.1, even if the server
+	// supports HTTP/2.
+	ProtocolHTTP1 = "http1.1"
+
+	// ProtocolHTTP2 makes the request using HTTP/2 over TLS, failing if the
+	// server does not support it.
+	ProtocolHTTP2 = "h2"
+
+	// ProtocolH2C makes the request using HTTP/2 without TLS, connecting with
+	// prior knowledge rather than upgrading from HTTP/1.1.
+	ProtocolH2C = "h2c"
+)
+
+// Protocol returns the protocol that will be used to make the request. The
+// protocol set by the request is used over the one set by the project.
+func (r Request) Protocol() string {
+	if r.Spec.Protocol != "" {
+		return r.Spec.Protocol
+	}
+	return r.scope().Defaults.Protocol
+}
+
+// NegotiatedProtocol returns the protocol that the response was sent using, as
+// one of the protocols that a request can be made with.
+func NegotiatedProtocol(r *http.Response) string {
+	switch {
+	case r.ProtoMajor == 2 && r.TLS == nil:
+		return ProtocolH2C
+	case r.ProtoMajor == 2:
+		return ProtocolHTTP2
+	case r.ProtoMajor == 1 && r.ProtoMinor == 1:
+		return ProtocolHTTP1
+	}
+	return r.Proto
+}
+
+// Transport represents how connections are made for a request, in place of
+// connecting to the host and port of its url.
+//
+// If UnixSocket is set, every connection is made to the Unix domain socket at
+// that path. Otherwise, each entry of Resolve, given as host:port:address,
+// connects to the address rather than looking up the host, as curl's --resolve
+// does. The port can be * to match any port.
+type Transport struct {
+	UnixSocket string   `json:"unixSocket"`
+	Resolve    []string `json:"resolve"`
+}
+
+// Transport returns how connections are made for the request. A unix socket
+// set by the request is used over one set by the project, and the resolve
+// entries of both are used, with those of the request being looked at first.
+func (r Request) Transport() *Transport {
+	rt, pt := r.Spec.Transport, r.scope().Defaults.Transport
+	if rt == nil {
+		return pt.clone()
+	}
+	t := rt.clone()
+	if pt != nil {
+		if t.UnixSocket == "" {
+			t.UnixSocket = pt.UnixSocket
+		}
+		t.Resolve = append(t.Resolve, pt.Resolve...)
+	}
+	return t
+}
+
+// clone returns a copy of the transport.
+func (t *Transport) clone() *Transport {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	c.Resolve = append([]string(nil), t.Resolve...)
+	return &c
+}
+
+// strings returns each string within the transport that may contain variables.
+func (t Transport) strings() []string {
+	return append([]string{t.UnixSocket}, t.Resolve...)
+}
+
+// parse replaces any variables within the transport.
+func (t *Transport) parse(parse parser) {
+	t.UnixSocket = parse(t.UnixSocket)
+	for i, r := range t.Resolve {
+		t.Resolve[i] = parse(r)
+	}
+}
+
+// dialContext returns the function used to make connections for the
+// transport, wrapping the given dialer.
+func (t Transport) dialContext(d *net.Dialer) (
+	func(ctx context.Context, network, addr string) (net.Conn, error), error) {
+	if t.UnixSocket != "" {
+		return func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return d.DialContext(ctx, "unix", t.UnixSocket)
+		}, nil
+	}
+
+	type entry struct{ host, port, address string }
+	var entries []entry
+	for _, r := range t.Resolve {
+		parts := strings.SplitN(r, ":", 3)
+		if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+			return nil, fmt.Errorf(
+				"Resolve \"%s\" must be in the format host:port:address.", r)
+		}
+		entries = append(entries, entry{
+			host:    strings.ToLower(parts[0]),
+			port:    parts[1],
+			address: strings.Trim(parts[2], "[]"),
+		})
+	}
+
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return d.DialContext(ctx, network, addr)
+		}
+		for _, e := range entries {
+			if e.host == strings.ToLower(host) && (e.port == port || e.port == "*") {
+				addr = net.JoinHostPort(e.address, port)
+				break
+			}
+		}
+		return d.DialContext(ctx, network, addr)
+	}, nil
+}
+
+// dialer returns the dialer used to make connections, with the same settings as
+// the default transport.
+func dialer() *net.Dialer {
+	return &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+}
+
+// transportKey is everything that a transport is built from. Requests that are
+// made with the same key share a transport, so that its connections are reused
+// rather than a new pool being left open for every request.
+type transportKey struct {
+	protocol   string
+	grpc       bool
+	tls        bool
+	unixSocket string
+	resolve    string
+	proxy      bool
+	proxyURL   string
+	noProxy    string
+}
+
+// transports holds the transport built for each key.
+var transports = struct {
+	sync.Mutex
+	m map[transportKey]*http.Transport
+}{m: map[transportKey]*http.Transport{}}
+
+// key returns the key of the transport used to make the request.
+func (hr httpRequest) key() (transportKey, error) {
+	u, err := url.Parse(hr.url)
+	if err != nil {
+		return transportKey{}, err
+	}
+	k := transportKey{
+		protocol: hr.protocol,
+		grpc:     hr.grpc != nil,
+		tls:      u.Scheme == "https",
+	}
+	if hr.dial != nil {
+		k.unixSocket = hr.dial.UnixSocket
+		k.resolve = strings.Join(hr.dial.Resolve, "\n")
+	}
+	if hr.proxy != nil {
+		k.proxy, k.proxyURL = true, hr.proxy.URL
+		k.noProxy = strings.Join(hr.proxy.NoProxy, "\n")
+	}
+	return k, nil
+}
+
+// transport returns the transport used to make the request, which is shared by
+// every request made with the same protocol, connections and proxy.
+func (hr httpRequest) transport() (*http.Transport, error) {
+	k, err := hr.key()
+	if err != nil {
+		return nil, err
+	}
+
+	transports.Lock()
+	defer transports.Unlock()
+	if t, ok := transports.m[k]; ok {
+		return t, nil
+	}
+	t, err := hr.newTransport()
+	if err != nil {
+		return nil, err
+	}
+	transports.m[k] = t
+	return t, nil
+}
+
+// newTransport builds the transport used to make the request. Compression is
+// handled by httpu rather than the transport, so that responses are decoded in
+// the same way whether or not Accept-Encoding has been set by the request.
+//
+// Connections are made as set by the request's Transport, if there is one, and
+// through its proxy, which replaces any proxy set by the environment.
+//
+// gRPC calls can only be made over HTTP/2, which is used without TLS by
+// connecting with prior knowledge when the project url is not https, unless
+// another protocol has been set.
+func (hr httpRequest) newTransport() (*http.Transport, error) {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.DisableCompression = true
+
+	if hr.dial != nil {
+		dc, err := hr.dial.dialContext(dialer())
+		if err != nil {
+			return nil, err
+		}
+		t.DialContext = dc
+	}
+
+	if hr.proxy != nil {
+		pf, err := hr.proxy.proxyFunc()
+		if err != nil {
+			return nil, err
+		}
+		t.Proxy = pf
+	}
+
+	u, err := url.Parse(hr.url)
+	if err != nil {
+		return nil, err
+	}
+	tls := u.Scheme == "https"
+
+	p := new(http.Protocols)
+	switch hr.protocol {
+	case "":
+		if hr.grpc == nil {
+			return t, nil
+		}
+		p.SetHTTP2(true)
+		p.SetUnencryptedHTTP2(true)
+	case ProtocolHTTP1:
+		p.SetHTTP1(true)
+	case ProtocolHTTP2:
+		if !tls {
+			return nil, fmt.Errorf(
+				"Protocol h2 needs an https url, h2c can be used without TLS.")
+		}
+		p.SetHTTP2(true)
+	case ProtocolH2C:
+		if tls {
+			return nil, fmt.Errorf(
+				"Protocol h2c can not be used with an https url, use h2 instead.")
+		}
+		p.SetUnencryptedHTTP2(true)
+	default:
+		return nil, fmt.Errorf(
+			"Unknown protocol \"%s\", expected http1.1, h2 or h2c.", hr.protocol)
+	}
+	t.Protocols = p
+	return t, nil
+}
+
+// tokenClient returns the client that oauth2 tokens are obtained from the given
+// token url with, whose transport is built in the same way as that of the
+// request, so that tokens are obtained through the same proxy, connections and
+// protocol.
+func (hr httpRequest) tokenClient(tokenURL *url.URL) (*http.Client, error) {
+	tr := hr
+	tr.url, tr.grpc = tokenURL.String(), nil
+	t, err := tr.transport()
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{Transport: t, Timeout: 30 * time.Second}, nil
+}
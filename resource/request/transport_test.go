@@ -0,0 +1,128 @@
+package request
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMakeWithProtocol(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(r.Proto))
+		}))
+	server.Config.Protocols = new(http.Protocols)
+	server.Config.Protocols.SetHTTP1(true)
+	server.Config.Protocols.SetUnencryptedHTTP2(true)
+	server.Start()
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+
+	r := Request{Scope: NewScope(nil), Spec: RequestSpec{Uri: "/", Method: "GET"}}
+	resp, _, err := r.Make(*u)
+	assert.Nil(t, err)
+	assert.Equal(t, "HTTP/1.1", readBody(t, resp))
+	assert.Equal(t, ProtocolHTTP1, NegotiatedProtocol(resp))
+
+	// The protocol of the project is used unless the request sets its own.
+	r.Scope.Defaults.Protocol = ProtocolH2C
+	resp, _, err = r.Make(*u)
+	assert.Nil(t, err)
+	assert.Equal(t, "HTTP/2.0", readBody(t, resp))
+	assert.Equal(t, ProtocolH2C, NegotiatedProtocol(resp))
+
+	r.Spec.Protocol = ProtocolHTTP1
+	resp, _, err = r.Make(*u)
+	assert.Nil(t, err)
+	assert.Equal(t, "HTTP/1.1", readBody(t, resp))
+
+	r.Spec.Protocol = ProtocolHTTP2
+	_, _, err = r.Make(*u)
+	assert.EqualError(t, err, "Could not construct request: "+
+		"Protocol h2 needs an https url, h2c can be used without TLS.")
+
+	r.Spec.Protocol = "http3"
+	_, _, err = r.Make(*u)
+	assert.EqualError(t, err, "Could not construct request: "+
+		"Unknown protocol \"http3\", expected http1.1, h2 or h2c.")
+}
+
+func TestTransportProtocols(t *testing.T) {
+	hr := httpRequest{url: "https://example.com", protocol: ProtocolHTTP2}
+	tr, err := hr.transport()
+	assert.Nil(t, err)
+	assert.True(t, tr.Protocols.HTTP2())
+	assert.False(t, tr.Protocols.HTTP1())
+
+	hr.protocol = ProtocolH2C
+	_, err = hr.transport()
+	assert.EqualError(t, err,
+		"Protocol h2c can not be used with an https url, use h2 instead.")
+
+	// gRPC calls are made using HTTP/2 unless another protocol is set.
+	hr = httpRequest{url: "http://example.com", grpc: &GRPC{}}
+	tr, err = hr.transport()
+	assert.Nil(t, err)
+	assert.True(t, tr.Protocols.UnencryptedHTTP2())
+	assert.False(t, tr.Protocols.HTTP1())
+
+	// Requests made with the same settings share a transport.
+	same, err := hr.transport()
+	assert.Nil(t, err)
+	assert.True(t, tr == same)
+	hr.proxy = &Proxy{}
+	direct, err := hr.transport()
+	assert.Nil(t, err)
+	assert.True(t, tr != direct)
+	assert.Nil(t, direct.Proxy)
+}
+
+func TestMakeWithTransport(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Host))
+	})
+
+	sock := filepath.Join(t.TempDir(), "api.sock")
+	l, err := net.Listen("unix", sock)
+	assert.Nil(t, err)
+	unix := httptest.NewUnstartedServer(handler)
+	unix.Listener = l
+	unix.Start()
+	defer unix.Close()
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+
+	r := Request{Scope: NewScope(nil), Spec: RequestSpec{Uri: "/", Method: "GET"}}
+	u, _ := url.Parse("http://docker")
+
+	r.Scope.Defaults.Transport = &Transport{UnixSocket: sock}
+	resp, _, err := r.Make(*u)
+	assert.Nil(t, err)
+	assert.Equal(t, "docker", readBody(t, resp))
+
+	// The request's resolve entries are used along with the project's unix
+	// socket, which is used over them.
+	r.Spec.Transport = &Transport{Resolve: []string{"api.test:*:127.0.0.1"}}
+	assert.Equal(t, &Transport{
+		UnixSocket: sock,
+		Resolve:    []string{"api.test:*:127.0.0.1"},
+	}, r.Transport())
+
+	r.Scope.Defaults.Transport = nil
+	u, _ = url.Parse("http://api.test:" + port)
+	resp, _, err = r.Make(*u)
+	assert.Nil(t, err)
+	assert.Equal(t, "api.test:"+port, readBody(t, resp))
+
+	r.Spec.Transport.Resolve = []string{"api.test:127.0.0.1"}
+	_, _, err = r.Make(*u)
+	assert.EqualError(t, err, "Could not construct request: "+
+		"Resolve \"api.test:127.0.0.1\" must be in the format host:port:address.")
+}
@@ -4,21 +4,39 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/url"
+	"strings"
 
+	"github.com/hazbo/httpu/resource/request/auth"
+	"github.com/hazbo/httpu/resource/request/signer"
 	"github.com/hazbo/httpu/stash"
 )
 
 // RequestVariant represents a given variant for an HTTP request. This could be
 // related to the same resource, but using a different request method or path
 // for example.
+//
+// Anything that the variant does not set is inherited from its request, as is
+// anything set by what it extends, which is either another of its request's
+// variants, another request, or a variant of another request given as
+// request.variant.
 type Variant struct {
 	Name        string            `json:"name"`
+	Extends     string            `json:"extends"`
 	Path        string            `json:"path"`
 	Method      string            `json:"method"`
 	Data        requestData       `json:"data"`
 	FormData    url.Values        `json:"formData"`
+	Multipart   MultipartFields   `json:"multipart"`
+	Query       url.Values        `json:"query"`
+	PathParams  map[string]string `json:"pathParams"`
 	Headers     http.Header       `json:"headers"`
+	Auth        *auth.Auth        `json:"auth"`
+	Sign        *signer.Config    `json:"sign"`
+	GraphQL     *GraphQL          `json:"graphql"`
+	GRPC        *GRPC             `json:"grpc"`
+	Message     json.RawMessage   `json:"message"`
 	StashValues stash.StashValues `json:"stashValues"`
+	Assertions  Assertions        `json:"assertions"`
 }
 
 func (v *Variant) UnmarshalJSON(j []byte) error {
@@ -32,6 +50,10 @@ func (v *Variant) UnmarshalJSON(j []byte) error {
 			Name  string `json:"name"`
 			Value string `json:"value"`
 		} `json:"formData"`
+		Query []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"query"`
 		*Alias
 	}{
 		Alias: (*Alias)(v),
@@ -58,15 +80,129 @@ func (v *Variant) UnmarshalJSON(j []byte) error {
 
 	v.FormData = f
 
-	// TODO: move this into the modifier
-	if len(v.Data.contents) > 0 {
-		return nil
+	q := url.Values{}
+	for _, qobj := range aux.Query {
+		q.Add(qobj.Name, qobj.Value)
 	}
 
-	v.Data.loadContents()
+	v.Query = q
+
+	// Any data file is loaded as the variant is updated, along with those of
+	// its request, so that it is read from the file system of the project.
 	return nil
 }
 
+// Clone returns a deep copy of the variant, in the same way as Request.Clone.
+func (v Variant) Clone() Variant {
+	c := v
+	c.Headers = cloneHeader(v.Headers)
+	c.FormData = cloneValues(v.FormData)
+	c.Multipart = append(MultipartFields(nil), v.Multipart...)
+	c.Query = cloneValues(v.Query)
+	c.PathParams = mergePathParams(v.PathParams, nil)
+	c.StashValues = append(stash.StashValues(nil), v.StashValues...)
+	c.Auth = cloneAuth(v.Auth)
+	c.Sign = cloneSigner(v.Sign)
+	c.GraphQL = v.GraphQL.clone()
+	c.GRPC = v.GRPC.clone()
+	c.Message = append(json.RawMessage(nil), v.Message...)
+	c.Assertions = append(Assertions(nil), v.Assertions...)
+	return c
+}
+
+// Inherit returns a copy of the variant with each field that it does not set
+// taken from the given parent. The body is inherited as a whole, so a variant
+// that sets any of data, form data or multipart fields uses none of those of
+// its parent. A variant that sets a method other than that of its parent, such
+// as a GET variant of a POST request, does not inherit the body at all. Headers,
+// query and path parameters are merged, with those set by the variant replacing
+// those of the parent with the same name.
+func (v Variant) Inherit(p Variant) Variant {
+	c := v.Clone()
+	if c.Path == "" {
+		c.Path = p.Path
+	}
+	sameMethod := c.Method == "" || strings.EqualFold(c.Method, p.Method)
+	if c.Method == "" {
+		c.Method = p.Method
+	}
+	if sameMethod && c.Data.empty() && len(c.FormData) == 0 &&
+		len(c.Multipart) == 0 {
+		c.Data = p.Data
+		c.FormData = cloneValues(p.FormData)
+		c.Multipart = append(MultipartFields(nil), p.Multipart...)
+	}
+	if len(p.Query) > 0 {
+		c.Query = mergeQuery(p.Query, c.Query)
+	}
+	if len(p.PathParams) > 0 {
+		c.PathParams = mergePathParams(p.PathParams, c.PathParams)
+	}
+	if len(p.Headers) > 0 {
+		h := cloneHeader(p.Headers)
+		for k, hv := range c.Headers {
+			h[k] = hv
+		}
+		c.Headers = h
+	}
+	if c.Auth == nil {
+		c.Auth = cloneAuth(p.Auth)
+	}
+	if c.Sign == nil {
+		c.Sign = cloneSigner(p.Sign)
+	}
+	if p.GraphQL != nil {
+		c.GraphQL = mergeGraphQL(p.GraphQL, c.GraphQL)
+	}
+	if p.GRPC != nil {
+		c.GRPC = mergeGRPC(p.GRPC, &Variant{GRPC: c.GRPC})
+	}
+	if len(c.Message) == 0 {
+		c.Message = append(json.RawMessage(nil), p.Message...)
+	}
+	if len(c.StashValues) == 0 {
+		c.StashValues = append(stash.StashValues(nil), p.StashValues...)
+	}
+	if len(c.Assertions) == 0 {
+		c.Assertions = append(Assertions(nil), p.Assertions...)
+	}
+	return c
+}
+
+// strings returns each string within the variant that may contain variables.
+func (v Variant) strings() []string {
+	ss := []string{v.Path, v.Method, v.Data.String()}
+	for _, uv := range v.FormData {
+		ss = append(ss, uv...)
+	}
+	for _, mf := range v.Multipart {
+		ss = append(ss, mf.Value, mf.File, mf.Filename)
+	}
+	for _, qv := range v.Query {
+		ss = append(ss, qv...)
+	}
+	for _, pv := range v.PathParams {
+		ss = append(ss, pv)
+	}
+	for _, hv := range v.Headers {
+		ss = append(ss, hv...)
+	}
+	if v.Auth != nil {
+		ss = append(ss, v.Auth.Strings()...)
+	}
+	if v.Sign != nil {
+		ss = append(ss, v.Sign.Strings()...)
+	}
+	if v.GraphQL != nil {
+		ss = append(ss, v.GraphQL.strings()...)
+	}
+	if v.GRPC != nil {
+		ss = append(ss, v.GRPC.strings()...)
+	}
+	ss = append(ss, string(v.Message))
+	return ss
+}
+
 // RequestVariants represent multiple RequestVariant
 type Variants []Variant
 
@@ -0,0 +1,222 @@
+package request
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/buger/jsonparser"
+	"github.com/gorilla/websocket"
+	"github.com/hazbo/httpu/stash"
+)
+
+// Frame represents a single message sent or received over a WebSocket.
+type Frame struct {
+	Time     time.Time
+	Incoming bool
+	Binary   bool
+	Data     []byte
+
+	// Err is set on the last frame, once the connection has been closed.
+	Err error
+}
+
+// WebSocket represents an open WebSocket connection for a websocket resource.
+type WebSocket struct {
+	// Protocol is the subprotocol chosen by the server, if any.
+	Protocol string
+
+	conn        *websocket.Conn
+	stashValues stash.StashValues
+	stash       *stash.Map
+	onFrame     func(Frame)
+
+	// mu guards writes, which can not be made concurrently.
+	mu sync.Mutex
+}
+
+// Connect opens a WebSocket connection for the request, using the uri of the
+// request relative to the base url, with the scheme being changed to ws or wss.
+// Headers, query parameters and auth are sent with the handshake. Each frame
+// received is passed to onFrame, as is each frame sent, until the connection
+// is closed.
+func (r *Request) Connect(baseURL url.URL, onFrame func(Frame)) (*WebSocket, error) {
+	s := r.scope()
+	r.Spec.UpdateWith(s)
+
+	uri, err := expandPath(r.Spec.Uri, r.Spec.PathParams)
+	if err != nil {
+		return nil, fmt.Errorf("Could not construct request: %s", err)
+	}
+
+	hr := httpRequest{
+		url:     fmt.Sprintf("%s%s", baseURL.String(), uri),
+		method:  http.MethodGet,
+		headers: r.Spec.Headers,
+		query:   r.Spec.Query,
+		auth:    s.parseAuth(r.Auth(nil)),
+		dial:    s.parseTransport(r.Transport()),
+		proxy:   s.parseProxy(r.Proxy()),
+	}
+
+	if len(r.Spec.StashValues) > 0 && s.Stash == nil {
+		return nil, fmt.Errorf("Could not construct request: " +
+			"there is no stash to keep its stash values in.")
+	}
+
+	u, err := hr.fullURL()
+	if err != nil {
+		return nil, fmt.Errorf("Could not construct request: %s", err)
+	}
+	u = webSocketURL(u)
+
+	// The handshake is built as any other request would be, so that auth can
+	// be applied to it in the same way.
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Could not construct request: %s", err)
+	}
+	req.Header = cloneHeader(hr.headers)
+	if req.Header == nil {
+		req.Header = http.Header{}
+	}
+	if hr.auth != nil {
+		hr.auth.TokenClient = hr.tokenClient
+		if err := hr.auth.Apply(req); err != nil {
+			return nil, fmt.Errorf("Could not construct request: %s", err)
+		}
+	}
+
+	d := websocket.Dialer{
+		HandshakeTimeout: 30 * time.Second,
+		Subprotocols:     r.Spec.Subprotocols,
+		Proxy:            http.ProxyFromEnvironment,
+	}
+	if hr.proxy != nil {
+		if d.Proxy, err = hr.proxy.proxyFunc(); err != nil {
+			return nil, fmt.Errorf("Could not construct request: %s", err)
+		}
+	}
+	if hr.dial != nil {
+		if d.NetDialContext, err = hr.dial.dialContext(dialer()); err != nil {
+			return nil, fmt.Errorf("Could not construct request: %s", err)
+		}
+	}
+	conn, resp, err := d.Dial(req.URL.String(), req.Header)
+	if err != nil {
+		if resp != nil {
+			return nil, fmt.Errorf("Could not connect: %s (%s)", err, resp.Status)
+		}
+		return nil, fmt.Errorf("Could not connect: %s", err)
+	}
+
+	ws := &WebSocket{
+		Protocol:    conn.Subprotocol(),
+		conn:        conn,
+		stashValues: append(stash.StashValues(nil), r.Spec.StashValues...),
+		stash:       s.Stash,
+		onFrame:     onFrame,
+	}
+	go ws.read()
+	return ws, nil
+}
+
+// webSocketURL changes the scheme of a http or https url to ws or wss.
+func webSocketURL(u string) string {
+	switch {
+	case strings.HasPrefix(u, "http://"):
+		return "ws://" + strings.TrimPrefix(u, "http://")
+	case strings.HasPrefix(u, "https://"):
+		return "wss://" + strings.TrimPrefix(u, "https://")
+	}
+	return u
+}
+
+// read reads each frame from the connection until it is closed.
+func (ws *WebSocket) read() {
+	for {
+		mt, data, err := ws.conn.ReadMessage()
+		if err != nil {
+			ws.onFrame(Frame{Time: time.Now(), Incoming: true, Err: err})
+			return
+		}
+
+		ws.applyStash(data)
+		ws.onFrame(Frame{
+			Time:     time.Now(),
+			Incoming: true,
+			Binary:   mt == websocket.BinaryMessage,
+			Data:     data,
+		})
+	}
+}
+
+// applyStash stores any stash values found within an incoming JSON frame.
+// Frames that do not contain a value leave what is already stashed as it is.
+func (ws *WebSocket) applyStash(data []byte) {
+	if len(ws.stashValues) == 0 || !json.Valid(data) {
+		return
+	}
+	for _, sv := range ws.stashValues {
+		v, _, _, err := jsonparser.Get(data, sv.JsonPath...)
+		if err != nil {
+			continue
+		}
+		sv.Value = string(v)
+		ws.stash.Set(sv.Name, sv)
+	}
+}
+
+// Send sends a text frame.
+func (ws *WebSocket) Send(data []byte) error {
+	ws.mu.Lock()
+	err := ws.conn.WriteMessage(websocket.TextMessage, data)
+	ws.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("Could not send message: %s", err)
+	}
+	ws.onFrame(Frame{Time: time.Now(), Data: data})
+	return nil
+}
+
+// Close closes the connection, telling the server that it is being closed
+// first.
+func (ws *WebSocket) Close() error {
+	ws.mu.Lock()
+	ws.conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+		time.Now().Add(time.Second))
+	ws.mu.Unlock()
+	return ws.conn.Close()
+}
+
+// Message returns the message to be sent for the given variant of a websocket
+// resource, once any variables have been replaced. Messages written as a JSON
+// string are sent as the string itself, with anything else being sent as the
+// JSON it was written as. Messages can also be loaded from a file using data.
+func (r Request) Message(v *Variant) ([]byte, error) {
+	sc := r.scope()
+	v.UpdateWith(sc)
+
+	if len(v.Message) == 0 {
+		if v.Data.FromFile != "" {
+			if err := v.Data.loadContents(sc.FS); err != nil {
+				return nil, fmt.Errorf("Could not load message: %s", err)
+			}
+		}
+		if len(v.Data.String()) == 0 {
+			return nil, fmt.Errorf("Variant \"%s\" has no message.", v.Name)
+		}
+		return []byte(v.Data.String()), nil
+	}
+
+	var s string
+	if err := json.Unmarshal(v.Message, &s); err == nil {
+		return []byte(s), nil
+	}
+	return v.Message, nil
+}
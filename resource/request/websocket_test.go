@@ -0,0 +1,99 @@
+package request
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/hazbo/httpu/resource/request/auth"
+	"github.com/hazbo/httpu/stash"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConnect(t *testing.T) {
+	var token string
+	upgrader := websocket.Upgrader{Subprotocols: []string{"chat"}}
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			token = r.Header.Get("Authorization")
+			c, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				return
+			}
+			defer c.Close()
+			for {
+				mt, msg, err := c.ReadMessage()
+				if err != nil {
+					return
+				}
+				c.WriteMessage(mt, []byte(`{"echo": `+string(msg)+`, "id": "abc"}`))
+			}
+		}))
+	defer server.Close()
+
+	r := Request{
+		Kind:  KindWebSocket,
+		Name:  "ws",
+		Scope: NewScope(nil),
+		Spec: RequestSpec{
+			Uri:          "/socket",
+			Headers:      http.Header{},
+			Auth:         &auth.Auth{Type: auth.Bearer, Token: "t"},
+			Subprotocols: []string{"chat"},
+			StashValues: stash.StashValues{
+				stash.StashValue{Name: "socketId", JsonPath: []string{"id"}},
+			},
+			Variants: Variants{
+				Variant{Name: "hello", Message: json.RawMessage(`{"type": "hello"}`)},
+				Variant{Name: "text", Message: json.RawMessage(`"ping"`)},
+			},
+		},
+	}
+
+	frames := make(chan Frame, 10)
+	u, _ := url.Parse(server.URL)
+	ws, err := r.Connect(*u, func(f Frame) { frames <- f })
+	assert.Nil(t, err)
+	assert.Equal(t, "chat", ws.Protocol)
+	assert.Equal(t, "Bearer t", token)
+
+	msg, err := r.Message(&r.Spec.Variants[0])
+	assert.Nil(t, err)
+	assert.Nil(t, ws.Send(msg))
+
+	next := func() Frame {
+		select {
+		case f := <-frames:
+			return f
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for frame")
+		}
+		return Frame{}
+	}
+
+	sent := next()
+	assert.False(t, sent.Incoming)
+	assert.Equal(t, `{"type": "hello"}`, string(sent.Data))
+
+	got := next()
+	assert.True(t, got.Incoming)
+	assert.JSONEq(t, `{"echo": {"type": "hello"}, "id": "abc"}`, string(got.Data))
+	assert.Equal(t, `abc`, r.Scope.Stash.Parse("${stash[socketId]}"))
+
+	msg, err = r.Message(&r.Spec.Variants[1])
+	assert.Nil(t, err)
+	assert.Equal(t, "ping", string(msg))
+
+	ws.Close()
+	for f := next(); f.Err == nil; f = next() {
+	}
+}
+
+func TestWebSocketURL(t *testing.T) {
+	assert.Equal(t, "ws://localhost/a", webSocketURL("http://localhost/a"))
+	assert.Equal(t, "wss://localhost/a", webSocketURL("https://localhost/a"))
+}
@@ -4,11 +4,13 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
+	"net/http"
 	"sort"
+	"strings"
 
 	"github.com/buger/jsonparser"
 	"github.com/hazbo/httpu/resource/request"
+	"github.com/hazbo/httpu/schema"
 	utils "github.com/hazbo/httpu/utils/common"
 )
 
@@ -22,7 +24,7 @@ func (rm RequestMap) String() string {
 	var b bytes.Buffer
 	b.WriteString("Requests:\n\n")
 
-	for _, r := range allReqVars() {
+	for _, r := range rm.names() {
 		b.WriteString(fmt.Sprintf("%s\n", r))
 	}
 
@@ -32,8 +34,40 @@ func (rm RequestMap) String() string {
 var (
 	// Requests is a map of each Request resource accesseble by it's name.
 	Requests = RequestMap{}
+
+	// Templates is a map of each template resource by its name. Templates
+	// are kept apart from requests, as they cannot be made.
+	Templates = RequestMap{}
 )
 
+// Resources holds the requests and templates loaded from the resource files of
+// a project, along with the scope that the requests are made with, whose file
+// system the files are read from.
+type Resources struct {
+	Scope     *request.Scope
+	Requests  RequestMap
+	Templates RequestMap
+}
+
+// NewResources returns resources for a project with the given scope, with
+// nothing loaded.
+func NewResources(s *request.Scope) *Resources {
+	return &Resources{
+		Scope:     s,
+		Requests:  RequestMap{},
+		Templates: RequestMap{},
+	}
+}
+
+// scope returns the scope of the resources, which is the empty default scope
+// if they do not have one.
+func (rs *Resources) scope() *request.Scope {
+	if rs.Scope != nil {
+		return rs.Scope
+	}
+	return request.DefaultScope()
+}
+
 // Search searches through the loaded resources to see if there is a match for
 // the given query string. This will in turn call searchRequestVariants to find
 // the variants for that resource.
@@ -46,11 +80,17 @@ var (
 // Also we might not want to conflate retuning each variant for each request in
 // what's named SearchRequests.
 func SearchRequests(query string) []string {
+	return Requests.Search(query)
+}
+
+// Search searches through the requests within the map in the same way as the
+// SearchRequests func.
+func (rm RequestMap) Search(query string) []string {
 	var res []string
 	if query == "" {
 		return res
 	}
-	for _, rv := range allReqVars() {
+	for _, rv := range rm.names() {
 		if len(query) <= len(rv) && rv[:len(query)] == query {
 			res = append(res, rv)
 		}
@@ -58,12 +98,43 @@ func SearchRequests(query string) []string {
 	return res
 }
 
-// allReqVars gets all requests and variants then returns them in the format
-// {request}.{variant}, akin to how the user interacts with command bar
+// Find looks up a request by the name used within the command bar, being either
+// {request} or {request}.{variant}. A clone of the request is returned so that
+// it can be made without modifying the loaded resource. The variant is nil if
+// only the name of the request was given.
+func Find(name string) (request.Request, *request.Variant, error) {
+	return Requests.Find(name)
+}
+
+// Find looks up a request within the map in the same way as the Find func.
+func (rm RequestMap) Find(
+	name string) (request.Request, *request.Variant, error) {
+	rp := strings.SplitN(name, ".", 2)
+
+	r, ok := rm[rp[0]]
+	if !ok {
+		return request.Request{}, nil, fmt.Errorf(
+			"Request \"%s\" does not exist.", rp[0])
+	}
+	req := r.Clone()
+
+	if len(rp) == 1 {
+		return req, nil, nil
+	}
+
+	v, err := req.Variant(rp[1])
+	if err != nil {
+		return request.Request{}, nil, err
+	}
+	return req, &v, nil
+}
+
+// names gets all requests and variants within the map then returns them in the
+// format {request}.{variant}, akin to how the user interacts with command bar
 // included with the default user interface.
-func allReqVars() []string {
+func (rm RequestMap) names() []string {
 	var reqVars []string
-	for name, r := range Requests {
+	for name, r := range rm {
 		if r.Spec.Method != "" && r.Spec.Uri != "" {
 			reqVars = append(reqVars, fmt.Sprintf("%s", name))
 		}
@@ -90,17 +161,28 @@ func loadRequest(cfg []byte) (request.Request, error) {
 type FilePath string
 
 // Load attempts to load a given resource file and create a new resource based
-// on the 'kind' specefied within the config.
+// on the 'kind' specefied within the config. The file is read relative to the
+// working directory, and the resource is added to the global requests or
+// templates.
 func (fp FilePath) Load() error {
-	var (
-		res []byte
-		err error
-	)
+	return (&Resources{
+		Scope:     request.NewScope(utils.DirFS("./")),
+		Requests:  Requests,
+		Templates: Templates,
+	}).Load(fp)
+}
 
-	res, err = ioutil.ReadFile(fmt.Sprintf("%s/%s", utils.ProjectPath, string(fp)))
+// Load loads the given resource file from the file system of the scope, adding
+// the resource to the requests or templates. Each request is given the scope,
+// so that it is made with it.
+func (rs *Resources) Load(fp FilePath) error {
+	// Resource files written as YAML, TOML or JSON with comments are
+	// converted into plain JSON first.
+	d, err := fp.document(rs.scope().FS)
 	if err != nil {
 		return err
 	}
+	res := d.JSON
 
 	kind, _, _, err := jsonparser.Get(res, "kind")
 	if err != nil {
@@ -114,16 +196,136 @@ func (fp FilePath) Load() error {
 
 	// Check the kind and assign the name for the given resource to be stored
 	switch string(kind) {
-	case "request":
+	case request.KindRequest:
 		req, err := loadRequest(res)
 		if err != nil {
 			return err
 		}
-		req.Spec.Update()
-		Requests[string(name)] = req
+		rs.add(string(name), req)
+	case request.KindGraphQL:
+		req, err := loadGraphQL(res)
+		if err != nil {
+			return err
+		}
+		rs.add(string(name), req)
+	case request.KindWebSocket:
+		req, err := loadRequest(res)
+		if err != nil {
+			return err
+		}
+		// The connection is opened using a GET request, which also means that
+		// it is listed alongside the messages that can be sent over it.
+		req.Spec.Method = http.MethodGet
+		rs.add(string(name), req)
+	case request.KindGRPC:
+		req, err := loadGRPC(res)
+		if err != nil {
+			return err
+		}
+		rs.add(string(name), req)
+	case request.KindTemplate:
+		t, err := loadRequest(res)
+		if err != nil {
+			return err
+		}
+		rs.Templates[string(name)] = t
+	default:
+		return schema.Problems{d.Problem(
+			fmt.Sprintf("invalid kind \"%s\"", kind), "", "kind")}
+	}
+	return nil
+}
+
+// add adds the loaded request with the given name, having read any data files
+// that it uses and replaced its variables using the scope.
+func (rs *Resources) add(name string, req request.Request) {
+	req.Scope = rs.Scope
+	req.Spec.UpdateWith(rs.scope())
+	rs.Requests[name] = req
+}
+
+// Resolve applies the templates used by each of the loaded requests, followed by
+// what each of their variants extend. Both can be found within any resource
+// file, so this is done once every file has been loaded.
+func Resolve() error {
+	return (&Resources{Requests: Requests, Templates: Templates}).Resolve()
+}
+
+// Resolve resolves the requests in the same way as the Resolve func. Every
+// template and variant that could not be resolved is returned within the error,
+// as ResolveErrors.
+func (rs *Resources) Resolve() error {
+	var errs ResolveErrors
+	for _, e := range request.ApplyTemplates(rs.Requests, rs.Templates) {
+		errs = append(errs, e)
+	}
+	for _, e := range request.ResolveExtends(rs.Requests) {
+		errs = append(errs, e)
+	}
+	if len(errs) > 0 {
+		return errs
 	}
 	return nil
 }
 
+// ResolveErrors are the errors found while resolving requests, with those of
+// templates coming before those of variants.
+type ResolveErrors []error
+
+// Error returns each of the errors on its own line.
+func (es ResolveErrors) Error() string {
+	s := make([]string, len(es))
+	for i, e := range es {
+		s[i] = e.Error()
+	}
+	return strings.Join(s, "\n")
+}
+
+// loadGraphQL loads the config for a GraphQL request resource. GraphQL requests
+// are sent using POST unless another method has been set, with variants using
+// the method of the request unless they set their own.
+func loadGraphQL(cfg []byte) (request.Request, error) {
+	r, err := loadRequest(cfg)
+	if err != nil {
+		return request.Request{}, err
+	}
+	if r.Spec.GraphQL == nil {
+		return request.Request{}, fmt.Errorf(
+			"GraphQL request \"%s\" must have a graphql query.", r.Name)
+	}
+
+	if r.Spec.Method == "" {
+		r.Spec.Method = "POST"
+	}
+	for i, v := range r.Spec.Variants {
+		if v.Method == "" {
+			r.Spec.Variants[i].Method = r.Spec.Method
+		}
+	}
+	return r, nil
+}
+
+// loadGRPC loads the config for a gRPC request resource. Calls are always made
+// using POST, at the path of the method, which is set so that the request is
+// listed and shown in the same way as any other.
+func loadGRPC(cfg []byte) (request.Request, error) {
+	r, err := loadRequest(cfg)
+	if err != nil {
+		return request.Request{}, err
+	}
+	g := r.Spec.GRPC
+	if g == nil || g.Service == "" || g.Method == "" {
+		return request.Request{}, fmt.Errorf(
+			"gRPC request \"%s\" must have a grpc service and method.", r.Name)
+	}
+
+	r.Spec.Method = http.MethodPost
+	r.Spec.Uri = g.Path()
+	for i := range r.Spec.Variants {
+		r.Spec.Variants[i].Method = http.MethodPost
+	}
+	return r, nil
+}
+
 // FilePaths represents multiple filepaths.
 type FilePaths []FilePath
@@ -2,8 +2,10 @@ package resource
 
 import (
 	"testing"
+	"testing/fstest"
 
 	"github.com/hazbo/httpu/resource/request"
+	utils "github.com/hazbo/httpu/utils/common"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -46,4 +48,126 @@ func TestSearchRequests(t *testing.T) {
 	assert.Equal(t, 1, len(SearchRequests("testrequest2.t")), "there should be one request / variants")
 	assert.Equal(t, 1, len(SearchRequests("ano")), "there should be one request / variants")
 	assert.Equal(t, 0, len(SearchRequests("nothing")), "there should be zero request / variants")
+
+	// Other maps are searched and listed without the global requests.
+	rm := RequestMap{
+		"other": request.Request{Spec: request.RequestSpec{Uri: "/", Method: "GET"}},
+	}
+	assert.Equal(t, []string{"other"}, rm.Search("o"))
+	assert.Equal(t, "Requests:\n\nother\n", rm.String())
+}
+
+func TestFind(t *testing.T) {
+	Requests = map[string]request.Request{
+		"testrequest": request.Request{
+			Name: "testrequest",
+			Kind: "request",
+			Spec: request.RequestSpec{
+				Variants: request.Variants{
+					request.Variant{
+						Name: "testreqvar",
+					},
+				},
+			}},
+	}
+
+	r, v, err := Find("testrequest")
+	assert.Nil(t, err)
+	assert.Nil(t, v)
+	assert.Equal(t, "testrequest", r.Name)
+
+	r, v, err = Find("testrequest.testreqvar")
+	assert.Nil(t, err)
+	assert.Equal(t, "testreqvar", v.Name)
+
+	_, _, err = Find("testrequest.nothing")
+	assert.NotNil(t, err)
+
+	_, _, err = Find("nothing")
+	assert.NotNil(t, err)
+}
+
+func TestLoadYAML(t *testing.T) {
+	Requests = map[string]request.Request{}
+
+	assert.Nil(t, FilePath("../projects/test_project/requests/greet.yaml").Load())
+	r, v, err := Find("greet.quiet")
+	assert.Nil(t, err)
+	assert.Equal(t, "/anything", r.Spec.Uri)
+	assert.Equal(t, "GET", v.Method)
+	assert.Equal(t, "application/json", r.Spec.Headers.Get("Content-Type"))
+	assert.Equal(t, `{"greeting": "hello"}`, r.Spec.Data.String())
+}
+
+func TestResourcesLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"requests/user.yaml": {Data: []byte(
+			"kind: request\nname: user\nspec:\n  uri: /users/1\n  method: PUT\n" +
+				"  data:\n    fromFile: data/user.json\n")},
+		"data/user.json": {Data: []byte(`{"name": "hazbo"}`)},
+	}
+	Requests = RequestMap{}
+
+	rs := NewResources(request.NewScope(fsys))
+	assert.Nil(t, rs.Load("requests/user.yaml"))
+	assert.Nil(t, rs.Resolve())
+	r, _, err := rs.Requests.Find("user")
+	assert.Nil(t, err)
+	assert.Equal(t, rs.Scope, r.Scope)
+	assert.Equal(t, `{"name": "hazbo"}`, r.Spec.Data.String())
+	assert.Empty(t, Requests, "global requests should not be changed")
+
+	// Every request that could not be resolved is returned.
+	rs = NewResources(request.NewScope(fsys))
+	rs.Requests["a"] = request.Request{Name: "a", Spec: request.RequestSpec{
+		Template: "missing"}}
+	rs.Requests["b"] = request.Request{Name: "b", Spec: request.RequestSpec{
+		Variants: request.Variants{{Name: "v", Extends: "none"}}}}
+	err = rs.Resolve()
+	assert.Len(t, err.(ResolveErrors), 2)
+	assert.Contains(t, err.Error(), "Could not use template for request \"a\"")
+	assert.Contains(t, err.Error(), "\nCould not extend variant \"v\" of \"b\"")
+}
+
+func TestExpand(t *testing.T) {
+	fsys := utils.DirFS("../")
+
+	fps, err := FilePath("projects/modules_project/./requests/").Expand(fsys)
+	assert.Nil(t, err)
+	assert.Equal(t, FilePaths{
+		"projects/modules_project/requests/a.json",
+		"projects/modules_project/requests/b.json",
+	}, fps)
+
+	// Project files, such as projects/invalid_project/project.yaml, are never
+	// matched.
+	fps, err = FilePath("projects/*/**/*.yaml").Expand(fsys)
+	assert.Nil(t, err)
+	assert.Equal(t, FilePaths{
+		"projects/invalid_project/requests/search.yaml",
+		"projects/modules_project/extra/deep/api.yaml",
+		"projects/modules_project/extra/deep/c.yaml",
+		"projects/test_project/requests/greet.yaml",
+	}, fps)
+
+	fps, err = FilePath("projects/none/*.json").Expand(fsys)
+	assert.Nil(t, err)
+	assert.Empty(t, fps)
+
+	_, err = FilePath("projects/[/*.json").Expand(fsys)
+	assert.EqualError(t, err,
+		"invalid pattern \"projects/[/*.json\": syntax error in pattern")
+}
+
+func TestDirs(t *testing.T) {
+	fsys := utils.DirFS("../")
+
+	// Hidden directories, such as requests/.drafts, are never searched.
+	assert.Equal(t, []string{
+		"projects/modules_project/extra",
+		"projects/modules_project/extra/deep",
+	}, FilePath("projects/modules_project/extra/**/*.yaml").Dirs(fsys))
+	assert.Equal(t, []string{"projects/modules_project/requests"},
+		FilePath("projects/modules_project/requests").Dirs(fsys))
+	assert.Empty(t, FilePath("projects/modules_project/requests/a.json").Dirs(fsys))
 }
@@ -0,0 +1,240 @@
+package resource
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hazbo/httpu/resource/request"
+	"github.com/hazbo/httpu/schema"
+	utils "github.com/hazbo/httpu/utils/common"
+)
+
+// methods are the HTTP methods that requests and variants can be made using.
+var methods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+	http.MethodPatch, http.MethodDelete, http.MethodOptions, http.MethodTrace,
+	http.MethodConnect,
+}
+
+// document reads the resource file from the file system and converts it into
+// JSON. Any problem reading or parsing the file is returned as
+// schema.Problems.
+func (fp FilePath) document(fsys fs.FS) (*schema.Document, error) {
+	path := utils.DisplayPath(fsys, string(fp))
+	b, err := fs.ReadFile(fsys, utils.CleanPath(string(fp)))
+	if err != nil {
+		msg := err.Error()
+		if os.IsNotExist(err) {
+			msg = "file does not exist"
+		}
+		return nil, schema.Problems{{File: path, Message: msg}}
+	}
+	return schema.NewDocument(path, b)
+}
+
+// Validate checks each of the resource files against the resource schema,
+// along with what the schema cannot check: that the names of requests, and of
+// the variants within each request, are not used more than once, that requests
+// have a method, that the files that they load data from exist, and that the
+// templates used by requests, and what variants extend, exist. The files are
+// read from the given file system, as are the files that requests load data
+// from. Every problem found within the files is returned.
+func (fps FilePaths) Validate(fsys fs.FS) schema.Problems {
+	var (
+		fileProblems = make([]schema.Problems, len(fps))
+		names        = map[string]schema.Problem{}
+		requests     = RequestMap{}
+		templates    = RequestMap{}
+		files        = map[string]int{}
+		docs         = map[string]*schema.Document{}
+	)
+	for i, fp := range fps {
+		d, err := fp.document(fsys)
+		if err != nil {
+			fileProblems[i] = err.(schema.Problems)
+			continue
+		}
+		r, ps, ok := validateResource(fsys, d, names)
+		fileProblems[i] = ps
+		if !ok {
+			continue
+		}
+		if r.Kind == request.KindTemplate {
+			templates[r.Name] = r
+		} else if _, seen := requests[r.Name]; !seen {
+			requests[r.Name], files[r.Name], docs[r.Name] = r, i, d
+		}
+	}
+
+	// Templates, and what variants extend, can be within any of the files,
+	// so they are only checked once every file has been read.
+	problem := func(name, message, hint string, path ...string) {
+		fileProblems[files[name]] = append(fileProblems[files[name]],
+			docs[name].Problem(message, hint, path...))
+	}
+	for _, e := range request.ApplyTemplates(requests, templates) {
+		problem(e.Request, e.Message, e.Hint, "spec", "template")
+	}
+	for _, e := range request.ResolveExtends(requests) {
+		problem(e.Request, e.Message, e.Hint,
+			"spec", "variants", strconv.Itoa(e.Index), "extends")
+	}
+
+	var ps schema.Problems
+	for _, fps := range fileProblems {
+		fps.Sort()
+		ps = append(ps, fps...)
+	}
+	return ps
+}
+
+// validateResource validates a single resource file, where names holds where
+// each of the requests in the files before it were named. The decoded request
+// is returned, or false if it could not be decoded.
+func validateResource(fsys fs.FS, d *schema.Document,
+	names map[string]schema.Problem) (request.Request, schema.Problems, bool) {
+	// The rest of the checks are still made when the schema finds problems,
+	// as long as the request can be decoded.
+	ps := d.ValidateResource()
+	var r request.Request
+	if err := json.Unmarshal(d.JSON, &r); err != nil {
+		return r, ps, false
+	}
+
+	// Templates are named apart from requests, as they are never made.
+	kind, key := "request", r.Name
+	if r.Kind == request.KindTemplate {
+		kind, key = "template", "template "+r.Name
+	}
+	if first, ok := names[key]; ok {
+		ps = append(ps, d.Problem(
+			fmt.Sprintf("duplicate %s name \"%s\"", kind, r.Name),
+			fmt.Sprintf("first used at %s", first.Position()), "name"))
+	} else {
+		names[key] = d.Problem("", "", "name")
+	}
+	return r, append(ps, validateRequest(fsys, d, r)...), true
+}
+
+// LookedFor returns a hint giving the path that a file, relative to the root
+// of the file system, was looked for at. No hint is given when the root is the
+// current directory, or is not a directory on disk, as the path is the same.
+func LookedFor(fsys fs.FS, file string) string {
+	p := utils.DisplayPath(fsys, file)
+	if p == filepath.Clean(file) {
+		return ""
+	}
+	return fmt.Sprintf("looked for %s", p)
+}
+
+// validateRequest checks the variants, methods and data files of a request
+// that has already been validated against the schema.
+func validateRequest(
+	fsys fs.FS, d *schema.Document, r request.Request) schema.Problems {
+	var ps schema.Problems
+
+	// Requests without a method are not listed, so they can only be made
+	// using their variants.
+	if r.Kind == request.KindRequest && r.Spec.Method == "" &&
+		len(r.Spec.Variants) == 0 {
+		ps = append(ps, d.Problem("missing field \"method\"",
+			"requests without a method or variants cannot be made", "spec"))
+	}
+	ps = append(ps, validateMethod(d, r.Spec.Method, "spec", "method")...)
+	ps = append(ps, validateFiles(fsys, d, r.Spec.Data.FromFile, r.Spec.GraphQL,
+		r.Spec.Multipart, "spec")...)
+	ps = append(ps, validateAssertions(d, r.Spec.Assertions, "spec")...)
+	if _, err := time.ParseDuration(r.Spec.Timeout); r.Spec.Timeout != "" &&
+		err != nil {
+		ps = append(ps, d.Problem(
+			fmt.Sprintf("invalid timeout \"%s\"", r.Spec.Timeout),
+			"expected a duration such as \"10s\"", "spec", "timeout"))
+	}
+
+	variants := map[string]schema.Problem{}
+	for i, v := range r.Spec.Variants {
+		path := []string{"spec", "variants", strconv.Itoa(i)}
+		if first, ok := variants[v.Name]; ok {
+			ps = append(ps, d.Problem(
+				fmt.Sprintf("duplicate variant name \"%s\"", v.Name),
+				fmt.Sprintf("first used at %s", first.Position()),
+				append(path, "name")...))
+		} else {
+			variants[v.Name] = d.Problem("", "", append(path, "name")...)
+		}
+		ps = append(ps, validateMethod(d, v.Method, append(path, "method")...)...)
+		ps = append(ps, validateFiles(fsys, d, v.Data.FromFile, v.GraphQL,
+			v.Multipart, path...)...)
+		ps = append(ps, validateAssertions(d, v.Assertions, path...)...)
+	}
+	return ps
+}
+
+// validateMethod checks that the method is a known HTTP method, unless it is
+// set using a variable.
+func validateMethod(
+	d *schema.Document, method string, path ...string) schema.Problems {
+	if method == "" || strings.Contains(method, "${") {
+		return nil
+	}
+	for _, m := range methods {
+		if method == m {
+			return nil
+		}
+	}
+	return schema.Problems{d.Problem(
+		fmt.Sprintf("invalid method \"%s\"", method),
+		schema.Suggest(method, methods), path...)}
+}
+
+// validateAssertions checks that the regular expressions used by assertions
+// within the request or variant at the given path can be compiled.
+func validateAssertions(
+	d *schema.Document, as request.Assertions, path ...string) schema.Problems {
+	var ps schema.Problems
+	for i, a := range as {
+		if _, err := regexp.Compile(a.Matches); err != nil {
+			ps = append(ps, d.Problem(
+				fmt.Sprintf("invalid matches \"%s\", %s", a.Matches, err), "",
+				append(append([]string(nil), path...),
+					"assertions", strconv.Itoa(i), "matches")...))
+		}
+	}
+	return ps
+}
+
+// validateFiles checks that the data, GraphQL query and multipart files set
+// within the request or variant at the given path exist.
+func validateFiles(fsys fs.FS, d *schema.Document, fromFile string,
+	gql *request.GraphQL, mfs request.MultipartFields,
+	path ...string) schema.Problems {
+	var ps schema.Problems
+	check := func(file string, field ...string) {
+		if file == "" || strings.Contains(file, "${") {
+			return
+		}
+		if _, err := fs.Stat(fsys, utils.CleanPath(file)); err != nil {
+			ps = append(ps, d.Problem(
+				fmt.Sprintf("data file \"%s\" does not exist", file),
+				LookedFor(fsys, file),
+				append(append([]string(nil), path...), field...)...))
+		}
+	}
+
+	check(fromFile, "data", "fromFile")
+	if gql != nil {
+		check(gql.Query.FromFile, "graphql", "query", "fromFile")
+	}
+	for i, mf := range mfs {
+		check(mf.File, "multipart", strconv.Itoa(i), "file")
+	}
+	return ps
+}
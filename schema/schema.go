@@ -0,0 +1,374 @@
+// Package schema validates project and resource files against the JSON Schema
+// published with httpu, reporting the line and column of each problem found
+// within the file, along with a hint on how to fix it where there is one.
+//
+// The schemas can also be used by editors to complete and check files as they
+// are written, by setting "$schema" to their url within a JSON file.
+package schema
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hazbo/httpu/resource/format"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+const (
+	// ProjectURL is the url that the project schema is published at.
+	ProjectURL = "https://raw.githubusercontent.com/hazbo/httpu/master/schema/project.schema.json"
+
+	// ResourceURL is the url that the resource schema is published at.
+	ResourceURL = "https://raw.githubusercontent.com/hazbo/httpu/master/schema/resource.schema.json"
+)
+
+var (
+	//go:embed project.schema.json
+	projectSchema []byte
+
+	//go:embed resource.schema.json
+	resourceSchema []byte
+)
+
+var (
+	compileOnce sync.Once
+	project     *jsonschema.Schema
+	resource    *jsonschema.Schema
+
+	// raw holds each schema decoded as it is, so that the fields and values
+	// they allow can be given as hints.
+	raw = map[string]interface{}{}
+)
+
+// compile compiles both schemas the first time they are needed. They are
+// embedded, so any error is a bug that is caught by the tests.
+func compile() {
+	compileOnce.Do(func() {
+		c := jsonschema.NewCompiler()
+		for url, b := range map[string][]byte{
+			ProjectURL:  projectSchema,
+			ResourceURL: resourceSchema,
+		} {
+			if err := c.AddResource(url, bytes.NewReader(b)); err != nil {
+				panic(err)
+			}
+			var v interface{}
+			json.Unmarshal(b, &v)
+			raw[url] = v
+		}
+		project = c.MustCompile(ProjectURL)
+		resource = c.MustCompile(ResourceURL)
+	})
+}
+
+// Problem is a problem found within a project or resource file. The line and
+// column are zero when the position of the problem is not known.
+type Problem struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+	Hint    string
+}
+
+// String returns the problem along with where it is, in the form of
+// file:line:column: message (hint).
+func (p Problem) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %s", p.Position(), p.Message)
+	if p.Hint != "" {
+		fmt.Fprintf(&b, " (%s)", p.Hint)
+	}
+	return b.String()
+}
+
+// Position returns where the problem is, in the form of file:line:column, with
+// the line and column left out when they are not known.
+func (p Problem) Position() string {
+	switch {
+	case p.Line == 0:
+		return p.File
+	case p.Column == 0:
+		return fmt.Sprintf("%s:%d", p.File, p.Line)
+	}
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+}
+
+// Problems is a list of problems, which can be returned as an error.
+type Problems []Problem
+
+// Error returns each of the problems on its own line.
+func (ps Problems) Error() string {
+	s := make([]string, len(ps))
+	for i, p := range ps {
+		s[i] = p.String()
+	}
+	return strings.Join(s, "\n")
+}
+
+// Document is a project or resource file, converted into JSON.
+type Document struct {
+	// File is the path of the file, as it is shown within problems.
+	File string
+
+	// JSON is the file converted into JSON.
+	JSON []byte
+
+	source []byte
+	value  interface{}
+}
+
+// NewDocument converts the contents of a project or resource file into JSON,
+// based on the extension of the file. If the file cannot be parsed, the
+// problem is returned as Problems.
+func NewDocument(file string, b []byte) (*Document, error) {
+	j, err := format.ToJSON(file, b)
+	if err != nil {
+		p := Problem{File: file, Message: err.Error()}
+		if fe, ok := err.(*format.Error); ok {
+			p.Line, p.Column = fe.Line, fe.Column
+		}
+		return nil, Problems{p}
+	}
+
+	d := &Document{File: file, JSON: j, source: b}
+	dec := json.NewDecoder(bytes.NewReader(j))
+	dec.UseNumber()
+	if err := dec.Decode(&d.value); err != nil {
+		return nil, Problems{{File: file, Message: err.Error()}}
+	}
+	return d, nil
+}
+
+// Problem returns a problem found at the value with the given path within the
+// document, which is made up of object keys and array indexes.
+func (d *Document) Problem(message, hint string, path ...string) Problem {
+	p := Problem{File: d.File, Message: message, Hint: hint}
+	p.Line, p.Column = format.Locate(d.File, d.source, path)
+	return p
+}
+
+// ValidateProject validates the document against the project schema.
+func (d *Document) ValidateProject() Problems {
+	compile()
+	return d.validate(project)
+}
+
+// ValidateResource validates the document against the resource schema.
+func (d *Document) ValidateResource() Problems {
+	compile()
+	return d.validate(resource)
+}
+
+// validate validates the document against the schema, returning a problem for
+// each error found, in the order that they appear within the document.
+func (d *Document) validate(s *jsonschema.Schema) Problems {
+	err := s.Validate(d.value)
+	if err == nil {
+		return nil
+	}
+	ve, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return Problems{{File: d.File, Message: err.Error()}}
+	}
+
+	ps := d.problems(ve)
+	ps.Sort()
+	return ps
+}
+
+// Sort sorts the problems by where they are found within each file, keeping
+// the files in the order that they first appear.
+func (ps Problems) Sort() {
+	files := map[string]int{}
+	for i, p := range ps {
+		if _, ok := files[p.File]; !ok {
+			files[p.File] = i
+		}
+	}
+	sort.SliceStable(ps, func(i, j int) bool {
+		a, b := ps[i], ps[j]
+		switch {
+		case a.File != b.File:
+			return files[a.File] < files[b.File]
+		case a.Line != b.Line:
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+}
+
+// problems returns the problems for each of the errors that caused the given
+// validation error.
+func (d *Document) problems(ve *jsonschema.ValidationError) Problems {
+	if len(ve.Causes) > 0 {
+		var ps Problems
+		for _, c := range ve.Causes {
+			ps = append(ps, d.problems(c)...)
+		}
+		return ps
+	}
+
+	path := splitPointer(ve.InstanceLocation)
+	value := lookup(d.value, path)
+	schema := schemaAt(ve.AbsoluteKeywordLocation)
+	field := "value"
+	if len(path) > 0 {
+		field = path[len(path)-1]
+	}
+
+	keyword := ve.KeywordLocation[strings.LastIndexByte(ve.KeywordLocation, '/')+1:]
+	switch keyword {
+	case "additionalProperties":
+		parent, _ := schemaAt(parentOf(ve.AbsoluteKeywordLocation)).(map[string]interface{})
+		known := keys(parent["properties"])
+		var ps Problems
+		for _, k := range keys(value) {
+			if contains(known, k) {
+				continue
+			}
+			ps = append(ps, d.Problem(fmt.Sprintf("unknown field \"%s\"", k),
+				Suggest(k, known), append(path, k)...))
+		}
+		return ps
+	case "required":
+		var ps Problems
+		present := keys(value)
+		required, _ := schema.([]interface{})
+		for _, r := range required {
+			if k := fmt.Sprint(r); !contains(present, k) {
+				ps = append(ps, d.Problem(fmt.Sprintf("missing field \"%s\"", k),
+					"", path...))
+			}
+		}
+		return ps
+	case "enum", "const":
+		var allowed []string
+		if vs, ok := schema.([]interface{}); ok {
+			for _, v := range vs {
+				allowed = append(allowed, fmt.Sprint(v))
+			}
+		}
+		b, _ := json.Marshal(value)
+		return Problems{d.Problem(fmt.Sprintf("invalid %s %s", field, b),
+			Suggest(fmt.Sprint(value), allowed), path...)}
+	}
+	return Problems{d.Problem(fmt.Sprintf("invalid %s, %s", field, ve.Message),
+		"", path...)}
+}
+
+// Suggest returns a hint naming the closest of the given values to the one
+// that was given, or listing them all if none of them are close and there are
+// only a few of them.
+func Suggest(given string, values []string) string {
+	best, dist := "", -1
+	for _, v := range values {
+		d := distance(strings.ToLower(given), strings.ToLower(v))
+		if dist < 0 || d < dist {
+			best, dist = v, d
+		}
+	}
+	switch {
+	case best != "" && dist <= max(1, len(best)/3):
+		return fmt.Sprintf("did you mean \"%s\"?", best)
+	case len(values) > 0 && len(values) <= 9:
+		return fmt.Sprintf("expected one of %s", strings.Join(values, ", "))
+	}
+	return ""
+}
+
+// distance returns the edit distance between two strings, where swapping two
+// characters next to each other counts as a single edit.
+func distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
+
+// splitPointer splits a JSON pointer into the keys and indexes it is made of.
+func splitPointer(ptr string) []string {
+	if ptr == "" || ptr == "/" {
+		return nil
+	}
+	path := strings.Split(strings.TrimPrefix(ptr, "/"), "/")
+	for i, p := range path {
+		path[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(p)
+	}
+	return path
+}
+
+// parentOf returns the location that the given location is within.
+func parentOf(loc string) string {
+	return loc[:strings.LastIndexByte(loc, '/')]
+}
+
+// schemaAt returns the part of a raw schema at the given absolute location,
+// which is made up of the url of the schema and a JSON pointer.
+func schemaAt(loc string) interface{} {
+	url, ptr, _ := strings.Cut(loc, "#")
+	return lookup(raw[url], splitPointer(ptr))
+}
+
+// lookup returns the value at the given path within a decoded JSON value.
+func lookup(v interface{}, path []string) interface{} {
+	for _, p := range path {
+		switch vv := v.(type) {
+		case map[string]interface{}:
+			v = vv[p]
+		case []interface{}:
+			var i int
+			if _, err := fmt.Sscan(p, &i); err != nil || i >= len(vv) {
+				return nil
+			}
+			v = vv[i]
+		default:
+			return nil
+		}
+	}
+	return v
+}
+
+// keys returns the sorted keys of a decoded JSON object.
+func keys(v interface{}) []string {
+	m, _ := v.(map[string]interface{})
+	ks := make([]string, 0, len(m))
+	for k := range m {
+		ks = append(ks, k)
+	}
+	sort.Strings(ks)
+	return ks
+}
+
+// contains checks whether the value is within the list.
+func contains(list []string, v string) bool {
+	for _, l := range list {
+		if l == v {
+			return true
+		}
+	}
+	return false
+}
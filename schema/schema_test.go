@@ -0,0 +1,73 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateResource(t *testing.T) {
+	d, err := NewDocument("users.yaml", []byte(`kind: graphql
+name: users
+spec:
+  uri: /graphql
+  stream: "yes"
+  auth:
+    type: baerer
+  variants:
+    - path: /admin
+      hedaers: []
+`))
+	assert.Nil(t, err)
+	assert.Equal(t, Problems{
+		{File: "users.yaml", Line: 3, Column: 1,
+			Message: `missing field "graphql"`},
+		{File: "users.yaml", Line: 5, Column: 3,
+			Message: "invalid stream, expected boolean, but got string"},
+		{File: "users.yaml", Line: 7, Column: 5, Message: `invalid type "baerer"`,
+			Hint: `did you mean "bearer"?`},
+		{File: "users.yaml", Line: 9, Column: 7, Message: `missing field "name"`},
+		{File: "users.yaml", Line: 10, Column: 7,
+			Message: `unknown field "hedaers"`, Hint: `did you mean "headers"?`},
+	}, d.ValidateResource())
+}
+
+func TestValidateTemplate(t *testing.T) {
+	d, err := NewDocument("json.yaml", []byte(`kind: template
+name: jsonApi
+spec:
+  uri: /users
+  timeout: 10s
+  assertions:
+    - status: "200"
+    - heder: Content-Type
+`))
+	assert.Nil(t, err)
+	assert.Equal(t, Problems{
+		{File: "json.yaml", Line: 4, Column: 3, Message: `unknown field "uri"`,
+			Hint: "expected one of assertions, auth, headers, query, timeout"},
+		{File: "json.yaml", Line: 7, Column: 7,
+			Message: "invalid status, expected integer, but got string"},
+		{File: "json.yaml", Line: 8, Column: 7,
+			Message: `unknown field "heder"`, Hint: `did you mean "header"?`},
+	}, d.ValidateResource())
+}
+
+func TestValidateProject(t *testing.T) {
+	d, err := NewDocument("project.json", []byte(`{"project": {"protocol": "spdy"}}`))
+	assert.Nil(t, err)
+	assert.Equal(t, `project.json:1:14: invalid protocol "spdy" `+
+		`(expected one of http1.1, h2, h2c)`, d.ValidateProject().Error())
+
+	_, err = NewDocument("project.json", []byte(`{"project": }`))
+	assert.Equal(t, Problems{{File: "project.json", Line: 1, Column: 13,
+		Message: "Invalid JSON: invalid character '}' looking for beginning of value"}},
+		err)
+}
+
+func TestSuggest(t *testing.T) {
+	assert.Equal(t, `did you mean "request"?`,
+		Suggest("reqeust", []string{"request", "graphql"}))
+	assert.Equal(t, "expected one of request, graphql",
+		Suggest("rest", []string{"request", "graphql"}))
+}
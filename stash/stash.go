@@ -2,6 +2,8 @@ package stash
 
 import (
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/hazbo/httpu/utils/varparser"
 )
@@ -18,49 +20,118 @@ type StashValue struct {
 	JsonPath      []string `json:"jsonPath"`
 	Origin        string   `json:"origin"`
 	RepeatRequest bool     `json:"repeatRequest"`
+
+	// Expires is when the value should no longer be used, such as for a token
+	// that has been cached. Values that have no expiry are left as zero.
+	Expires time.Time `json:"-"`
+}
+
+// Expired returns true if the value has an expiry that has passed, or is due
+// to pass within the given amount of time.
+func (s StashValue) Expired(within time.Duration) bool {
+	return !s.Expires.IsZero() && time.Now().Add(within).After(s.Expires)
 }
 
-// store is a map of stash values referenced by name.
-type store map[string]StashValue
+// Map is a map of stash values referenced by name. Each project being used has
+// its own, held by the client it is loaded into.
+//
+// Requests can be made from multiple goroutines at once, so each map is guarded
+// by a lock of its own. The zero value is an empty map ready to use.
+type Map struct {
+	mu     sync.RWMutex
+	values map[string]StashValue
+}
 
 // Replace is used to replace a variable with a value stored inside of the
 // stash.
-func (s store) Replace(k string) string {
-	return s[k].Value
+func (m *Map) Replace(k string) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.values[k].Value
+}
+
+// Set sets a stash value in the map with an associated name.
+func (m *Map) Set(key string, value StashValue) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.values == nil {
+		m.values = map[string]StashValue{}
+	}
+	m.values[key] = value
+}
+
+// Delete removes a stash value from the map.
+func (m *Map) Delete(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.values, key)
+}
+
+// Get tries to lookup a stash value by name and returns it if it exists.
+func (m *Map) Get(key string) (StashValue, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	v, ok := m.values[key]
+	if !ok {
+		return StashValue{}, fmt.Errorf("Value not found in stash.")
+	}
+	return v, nil
+}
+
+// Snapshot returns a copy of the values within the map, which can be ranged
+// over while requests are still setting values within the map itself.
+func (m *Map) Snapshot() map[string]StashValue {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	c := make(map[string]StashValue, len(m.values))
+	for n, v := range m.values {
+		c[n] = v
+	}
+	return c
+}
+
+// Parse uses the built in varparser to find an instance of a variable, and in
+// this case replace it with a value that exists within the map.
+func (m *Map) Parse(s string) string {
+	return varparser.New("stash").Parse(s, m)
 }
 
 // Store is an instance of the stash store.
-var Store store = store{}
+var Store = &Map{}
 
 // StashValues represents multiple stash values.
 type StashValues []StashValue
 
 // Push pushes new stash values to the global stash store.
 func (sv *StashValues) Push() {
-	for _, s := range *sv {
-		Store[s.Name] = s
+	sv.PushTo(Store)
+}
+
+// PushTo pushes new stash values to the given map.
+func (sv StashValues) PushTo(m *Map) {
+	for _, s := range sv {
+		m.Set(s.Name, s)
 	}
 }
 
-// Set sets a stash value in the map with an associated name.
+// Set sets a stash value in the global store with an associated name.
 func Set(key string, value StashValue) {
-	Store[key] = value
+	Store.Set(key, value)
 }
 
-// Get tries to lookup a stash value by name and returns it if it exists.
+// Delete removes a stash value from the global store.
+func Delete(key string) {
+	Store.Delete(key)
+}
+
+// Get tries to lookup a stash value by name within the global store and
+// returns it if it exists.
 func Get(key string) (StashValue, error) {
-	var (
-		v  StashValue
-		ok bool
-	)
-	if v, ok = Store[key]; !ok {
-		return StashValue{}, fmt.Errorf("Value not found in stash.")
-	}
-	return v, nil
+	return Store.Get(key)
 }
 
 // Parse uses the built in varparser to find an instance of a variable, and in
 // this case replace it with a value that exists with in the stash store.
 func Parse(s string) string {
-	return varparser.New("stash").Parse(s, Store)
+	return Store.Parse(s)
 }
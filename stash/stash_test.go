@@ -7,3 +7,16 @@ import (
 func TestReplace(t *testing.T) {
 
 }
+
+func TestSnapshot(t *testing.T) {
+	m := &Map{}
+	m.Set("a", StashValue{Name: "a", Value: "1"})
+
+	s := m.Snapshot()
+	m.Set("b", StashValue{Name: "b", Value: "2"})
+	m.Delete("a")
+
+	if len(s) != 1 || s["a"].Value != "1" {
+		t.Errorf("snapshot should not change with the map, got %v", s)
+	}
+}
@@ -5,28 +5,29 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"text/tabwriter"
 
-	"github.com/hazbo/httpu"
 	"github.com/hazbo/httpu/resource"
 	"github.com/hazbo/httpu/resource/request"
+	"github.com/hazbo/httpu/resource/request/auth"
+	"github.com/hazbo/httpu/resource/request/signer"
 	"github.com/hazbo/httpu/ui/printer"
 	"github.com/jroimartin/gocui"
 )
 
-// makeRequest wraps httpu's req.Make, updates the relevant views and updates
-// the request spec.
+// makeRequest wraps httpu's req.Make and updates the request spec.
 func makeRequest(
 	req *request.Request) (*http.Response, request.RequestStat, error) {
 
-	s := httpu.Session()
-
-	resp, stat, err := req.Make(s.URL)
+	resp, stat, err := req.Make(client.Project().URL)
 	if err != nil {
 		return &http.Response{}, request.RequestStat{}, err
 	}
 
-	RequestView.Clear()
-	req.Spec.Update()
+	req.Update()
 
 	return resp, stat, nil
 }
@@ -37,14 +38,12 @@ func makeRequestWithVariant(
 	req *request.Request,
 	v *request.Variant) (*http.Response, request.RequestStat, error) {
 
-	s := httpu.Session()
-	resp, stat, err := req.MakeWithVariant(s.URL, v)
+	resp, stat, err := req.MakeWithVariant(client.Project().URL, v)
 	if err != nil {
 		return &http.Response{}, request.RequestStat{}, err
 	}
 
-	RequestView.Clear()
-	req.Spec.Update()
+	req.Update()
 
 	return resp, stat, nil
 }
@@ -53,6 +52,9 @@ func writeRequestDataVariant(r *request.Request, v *request.Variant) {
 	b := bytes.NewBufferString(printer.Color("Request:\n", printer.ColorGreen))
 	b.WriteString(fmt.Sprintf("%s: %s%s\n\n", v.Method, r.Spec.Uri, v.Path))
 
+	writeAuth(b, r.Auth(v))
+	writeSigner(b, r.Signer(v))
+
 	if len(v.Headers) > 0 {
 		b.WriteString(printer.Color("Headers:\n", printer.ColorGreen))
 	}
@@ -61,7 +63,22 @@ func writeRequestDataVariant(r *request.Request, v *request.Variant) {
 		b.WriteString(fmt.Sprintf("%s: %s\n", h, val[0]))
 	}
 
-	if len(v.FormData) == 0 && len(v.Data.String()) == 0 {
+	writeQuery(b, v.Query, len(v.Headers) > 0)
+
+	if g := r.GraphQL(v); g != nil {
+		writeGraphQL(b, g)
+		fmt.Fprint(RequestView, b.String())
+		return
+	}
+
+	if g := r.GRPC(v); g != nil {
+		writeGRPC(b, g)
+		fmt.Fprint(RequestView, b.String())
+		return
+	}
+
+	if len(v.FormData) == 0 && len(v.Multipart) == 0 &&
+		len(v.Data.String()) == 0 && !v.Data.Binary {
 		fmt.Fprint(RequestView, b.String())
 		return
 	}
@@ -70,9 +87,14 @@ func writeRequestDataVariant(r *request.Request, v *request.Variant) {
 
 	jp := printer.NewJSONPrinter()
 
-	if len(v.FormData) > 0 {
+	switch {
+	case len(v.Multipart) > 0:
+		writeMultipart(b, v.Multipart)
+	case v.Data.Binary:
+		b.WriteString(fmt.Sprintf("Binary: %s", v.Data.FromFile))
+	case len(v.FormData) > 0:
 		b.WriteString(fmt.Sprintf("%s", v.FormData.Encode()))
-	} else {
+	default:
 		jp.PrintString(b, v.Data.String())
 	}
 
@@ -84,6 +106,9 @@ func writeRequestData(r *request.Request) {
 	b := bytes.NewBufferString(printer.Color("Request:\n", printer.ColorGreen))
 	b.WriteString(fmt.Sprintf("%s: %s\n\n", r.Spec.Method, r.Spec.Uri))
 
+	writeAuth(b, r.Auth(nil))
+	writeSigner(b, r.Signer(nil))
+
 	if len(r.Spec.Headers) > 0 {
 		b.WriteString(printer.Color("Headers:\n", printer.ColorGreen))
 	}
@@ -92,7 +117,22 @@ func writeRequestData(r *request.Request) {
 		b.WriteString(fmt.Sprintf("%s: %s\n", h, val[0]))
 	}
 
-	if len(r.Spec.FormData) == 0 && len(r.Spec.Data.String()) == 0 {
+	writeQuery(b, r.Spec.Query, len(r.Spec.Headers) > 0)
+
+	if r.Spec.GraphQL != nil {
+		writeGraphQL(b, r.Spec.GraphQL)
+		fmt.Fprint(RequestView, b.String())
+		return
+	}
+
+	if r.Spec.GRPC != nil {
+		writeGRPC(b, r.Spec.GRPC)
+		fmt.Fprint(RequestView, b.String())
+		return
+	}
+
+	if len(r.Spec.FormData) == 0 && len(r.Spec.Multipart) == 0 &&
+		len(r.Spec.Data.String()) == 0 && !r.Spec.Data.Binary {
 		fmt.Fprint(RequestView, b.String())
 		return
 	}
@@ -101,23 +141,155 @@ func writeRequestData(r *request.Request) {
 
 	jp := printer.NewJSONPrinter()
 
-	if len(r.Spec.FormData) > 0 {
+	switch {
+	case len(r.Spec.Multipart) > 0:
+		writeMultipart(b, r.Spec.Multipart)
+	case r.Spec.Data.Binary:
+		b.WriteString(fmt.Sprintf("Binary: %s", r.Spec.Data.FromFile))
+	case len(r.Spec.FormData) > 0:
 		b.WriteString(fmt.Sprintf("%s", r.Spec.FormData.Encode()))
-	} else {
+	default:
 		jp.PrintString(b, r.Spec.Data.String())
 	}
 
 	fmt.Fprint(RequestView, b.String())
 }
 
+// writeAuth writes the auth scheme used by a request. Only the scheme is
+// written, never the credentials themselves.
+func writeAuth(b *bytes.Buffer, a *auth.Auth) {
+	if a == nil {
+		return
+	}
+	b.WriteString(printer.Color("Auth:\n", printer.ColorGreen))
+	b.WriteString(fmt.Sprintf("%s\n\n", a))
+}
+
+// writeSigner writes the signer used by a request, if there is one.
+func writeSigner(b *bytes.Buffer, sc *signer.Config) {
+	if sc == nil {
+		return
+	}
+	b.WriteString(printer.Color("Signed:\n", printer.ColorGreen))
+	b.WriteString(fmt.Sprintf("%s\n\n", sc))
+}
+
+// writeSignature writes the canonical string that was signed below the request
+// data when the server has rejected the request, so that it can be compared
+// with what the server expected.
+func writeSignature(r *http.Response, stat request.RequestStat) {
+	if stat.Signature == "" || (r.StatusCode != http.StatusUnauthorized &&
+		r.StatusCode != http.StatusForbidden) {
+		return
+	}
+	fmt.Fprint(RequestView, printer.Color(
+		"\n\nSignature rejected, canonical string:\n", printer.ColorRed))
+	fmt.Fprintf(RequestView, "%s\n", stat.Signature)
+}
+
+// writeAssertions writes whether each of the assertions checked against the
+// response passed, along with why those that failed did so.
+func writeAssertions(stat request.RequestStat) {
+	if len(stat.Assertions) == 0 {
+		return
+	}
+	fmt.Fprint(RequestView, printer.Color("\nAssertions:\n", printer.ColorGreen))
+	for _, ar := range stat.Assertions {
+		if ar.Passed() {
+			fmt.Fprint(RequestView, printer.Color(
+				fmt.Sprintf("✓ %s\n", ar.Assertion), printer.ColorGreen))
+			continue
+		}
+		fmt.Fprint(RequestView, printer.Color(
+			fmt.Sprintf("✗ %s: %s\n", ar.Assertion, ar.Err), printer.ColorRed))
+	}
+}
+
+// writeGraphQL writes the query and variables of a GraphQL request.
+func writeGraphQL(b *bytes.Buffer, g *request.GraphQL) {
+	b.WriteString(printer.Color("\nQuery:\n", printer.ColorGreen))
+	if g.OperationName != "" {
+		b.WriteString(fmt.Sprintf("Operation: %s\n", g.OperationName))
+	}
+	b.WriteString(fmt.Sprintf("%s\n", strings.TrimSpace(g.String())))
+
+	if len(g.Variables) == 0 {
+		return
+	}
+	b.WriteString(printer.Color("\nVariables:\n", printer.ColorGreen))
+	printer.NewJSONPrinter().PrintString(b, string(g.Variables))
+}
+
+// writeGRPC writes the method called by a gRPC request, along with the message
+// sent to it.
+func writeGRPC(b *bytes.Buffer, g *request.GRPC) {
+	b.WriteString(printer.Color("\nMethod:\n", printer.ColorGreen))
+	b.WriteString(fmt.Sprintf("%s/%s\n", g.Service, g.Method))
+
+	if len(g.Message) == 0 {
+		return
+	}
+	b.WriteString(printer.Color("\nMessage:\n", printer.ColorGreen))
+	printer.NewJSONPrinter().PrintString(b, string(g.Message))
+}
+
+// writeMultipart writes each field of a multipart body on its own line. Only
+// the names of the files being uploaded are written, not their contents.
+func writeMultipart(b *bytes.Buffer, mfs request.MultipartFields) {
+	for _, mf := range mfs {
+		b.WriteString(fmt.Sprintf("%s\n", mf))
+	}
+}
+
+// writeQuery writes the query parameters for a request as a table, with each
+// repeated key being written on its own row.
+func writeQuery(b *bytes.Buffer, q url.Values, spaced bool) {
+	if len(q) == 0 {
+		return
+	}
+
+	if spaced {
+		b.WriteString("\n")
+	}
+	b.WriteString(printer.Color("Query:\n", printer.ColorGreen))
+
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	tw := tabwriter.NewWriter(b, 0, 0, 2, ' ', 0)
+	for _, k := range keys {
+		for _, v := range q[k] {
+			fmt.Fprintf(tw, "%s\t%s\n", k, v)
+		}
+	}
+	tw.Flush()
+}
+
+// writeProgress writes how much of the request body has been sent so far, as a
+// percentage, in place of the time the request has taken.
+func writeProgress(pct int64) {
+	RequestTimeView.Clear()
+	fmt.Fprintf(RequestTimeView, "%d%%", pct)
+}
+
 func writeResponseData(r *http.Response, stat request.RequestStat) {
 	defer r.Body.Close()
 	b, _ := ioutil.ReadAll(r.Body)
 	ResponseView.Clear()
+	ResponseView.Autoscroll = false
 
 	jp := printer.NewJSONPrinter()
 	jp.PrintString(ResponseView, string(b))
 
+	writeResponseStatus(r, stat)
+}
+
+// writeResponseStatus writes the status code along with the protocol it was
+// sent using, the time taken and size of the response into their views.
+func writeResponseStatus(r *http.Response, stat request.RequestStat) {
 	StatusCodeView.Clear()
 	RequestTimeView.Clear()
 
@@ -130,8 +302,57 @@ func writeResponseData(r *http.Response, stat request.RequestStat) {
 		StatusCodeView.BgColor = gocui.ColorRed
 	}
 
-	fmt.Fprintf(StatusCodeView, "  %d", r.StatusCode)
+	fmt.Fprintf(StatusCodeView, " %d %s", r.StatusCode, protocolName(r))
 	fmt.Fprintf(RequestTimeView, "%dms", stat.Total)
+
+	writeSize(stat)
+}
+
+// protocolName returns a short name for the protocol that the response was sent
+// using, small enough to fit alongside the status code.
+func protocolName(r *http.Response) string {
+	if p := request.NegotiatedProtocol(r); p != request.ProtocolHTTP1 {
+		return p
+	}
+	return "h1"
+}
+
+// writeSize writes the size of the request and response bodies.
+func writeSize(stat request.RequestStat) {
+	SizeView.Clear()
+	fmt.Fprintf(SizeView, "↑ %s ↓ %s",
+		formatBodySize(stat.Request), formatBodySize(stat.Response))
+}
+
+// formatBodySize formats the size of a body for the size view. If the body was
+// compressed, both the compressed and decompressed sizes are shown.
+func formatBodySize(bs *request.BodySize) string {
+	if bs == nil {
+		return "-"
+	}
+	if bs.Encoded() == bs.Decoded() {
+		return formatBytes(bs.Decoded())
+	}
+	return fmt.Sprintf("%s/%s", formatBytes(bs.Encoded()), formatBytes(bs.Decoded()))
+}
+
+// formatBytes formats a number of bytes using the largest fitting unit.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%c", float64(n)/float64(div), "KMGT"[exp])
+}
+
+// requests returns the requests of the project being used.
+func requests() resource.RequestMap {
+	return resource.RequestMap(client.Project().Requests)
 }
 
 // defaultKeyPress is called at the end of each keypress in default mode
@@ -139,17 +360,57 @@ func writeResponseData(r *http.Response, stat request.RequestStat) {
 // request view.
 func defaultKeyPress(v *gocui.View) error {
 	if HttpuMode == CommandMode {
-		return nil
+		return commandKeyPress(v)
 	}
 	RequestView.Clear()
 
 	if cmdBarBuffer() == "" {
-		fmt.Fprint(RequestView, resource.Requests)
+		fmt.Fprint(RequestView, requests())
 		return nil
 	}
 
-	for _, rc := range resource.SearchRequests(cmdBarBuffer()) {
+	// Once the name of the request has been followed by a space, arguments for
+	// it are being entered instead.
+	if name, args := cmdBarArgs(); len(args) > 0 ||
+		strings.HasSuffix(cmdBarBuffer(), " ") {
+		writeArgCompletions(name, args, strings.HasSuffix(cmdBarBuffer(), " "))
+		return nil
+	}
+
+	for _, rc := range requests().Search(cmdBarBuffer()) {
 		fmt.Fprintf(RequestView, "%s\n", rc)
 	}
 	return nil
 }
+
+// writeArgCompletions writes the path parameters for the given request that
+// have not yet been passed as arguments and that match the argument currently
+// being typed, into the request view.
+func writeArgCompletions(name string, args []string, next bool) {
+	req, v, err := client.Find(name)
+	if err != nil {
+		return
+	}
+
+	var current string
+	if !next {
+		current, args = args[len(args)-1], args[:len(args)-1]
+	}
+
+	bound, _ := request.ParseArgs(args)
+
+	path := req.Spec.Uri
+	if v != nil {
+		path += v.Path
+	}
+	fmt.Fprintf(RequestView, "%s\n\n", path)
+
+	for _, p := range req.PathParams(v) {
+		if _, ok := bound.Fields[p]; ok {
+			continue
+		}
+		if strings.HasPrefix(p, current) {
+			fmt.Fprintf(RequestView, "%s=\n", p)
+		}
+	}
+}
@@ -3,9 +3,10 @@ package ui
 import (
 	"fmt"
 	"log"
+	"net/http"
 	"strings"
 
-	"github.com/hazbo/httpu/resource"
+	"github.com/hazbo/httpu/resource/request"
 	"github.com/jroimartin/gocui"
 )
 
@@ -31,6 +32,12 @@ func (u *Ui) setBindings() {
 		log.Panicln(err)
 	}
 
+	err = u.Gui.SetKeybinding(
+		"", gocui.KeyCtrlX, gocui.ModNone, stopStream)
+	if err != nil {
+		log.Panicln(err)
+	}
+
 	err = u.Gui.SetKeybinding(
 		cmdBar, gocui.KeyArrowUp, gocui.ModNone, switchModeCmd)
 	if err != nil {
@@ -66,6 +73,18 @@ func (u *Ui) setBindings() {
 	if err != nil {
 		log.Panicln(err)
 	}
+
+	err = u.Gui.SetKeybinding(
+		promptView, gocui.KeyEnter, gocui.ModNone, promptEnter)
+	if err != nil {
+		log.Panicln(err)
+	}
+
+	err = u.Gui.SetKeybinding(
+		promptView, gocui.KeyEsc, gocui.ModNone, promptCancel)
+	if err != nil {
+		log.Panicln(err)
+	}
 }
 
 // quit quits the program.
@@ -120,44 +139,112 @@ func defaultEnter(g *gocui.Gui, v *gocui.View) error {
 		return nil
 	}
 
-	rp := strings.Split(cmdBarBuffer(), ".")
+	name, args := cmdBarArgs()
 
-	if len(rp) == 2 {
-		req, ok := resource.Requests[rp[0]]
-		if !ok {
-			// There was no request find, we will fail sliently at this point.
-			return nil
-		}
-		v, err := req.Variant(rp[1])
-		if err != nil {
-			return err
-		}
-		resp, stat, err := makeRequestWithVariant(&req, &v)
-		if err != nil {
-			return err
+	req, rv, err := client.Find(name)
+	if err != nil {
+		// There was no request or variant found, we will fail sliently at this
+		// point.
+		return nil
+	}
+
+	a, err := request.ParseArgs(args)
+	if err != nil {
+		RequestView.Clear()
+		fmt.Fprintf(RequestView, "%s\n", err)
+		return nil
+	}
+	if err := a.Apply(&req, rv); err != nil {
+		RequestView.Clear()
+		fmt.Fprintf(RequestView, "%s\n", err)
+		return nil
+	}
+
+	// Any prompt variables used by the request need a value entered by the
+	// user before the request can be made.
+	return promptAll(g, req.Prompts(rv), func(g *gocui.Gui) error {
+		if req.Kind == request.KindWebSocket {
+			return connectWebSocket(g, &req, rv)
 		}
+		return execute(g, &req, rv)
+	})
+}
 
-		writeRequestDataVariant(&req, &v)
-		writeResponseData(resp, stat)
+// execute makes the given request in the background, using the variant if
+// there is one, so that the user interface can show the progress of the request
+// body being sent. Once a response has been received, both the request and
+// response data are written to their views.
+func execute(g *gocui.Gui, req *request.Request, v *request.Variant) error {
+	// Streams are made again when reconnecting, so a copy is kept of the
+	// request and variant as they were before being made.
+	orig := req.Clone()
+	orig.Progress = nil
+	var origV *request.Variant
+	if v != nil {
+		c := v.Clone()
+		origV = &c
 	}
 
-	if len(rp) == 1 {
-		req, ok := resource.Requests[rp[0]]
-		if !ok {
-			// There was no request find, we will fail sliently at this point
-			// also.
-			return nil
+	// Updates to the views are not guaranteed to happen in order, so progress
+	// is no longer written once the response has been.
+	var (
+		done bool
+		last int64 = -1
+	)
+
+	req.Progress = func(sent, total int64) {
+		pct := sent * 100 / total
+		if pct == last {
+			return
 		}
+		last = pct
+		g.Update(func(g *gocui.Gui) error {
+			if !done {
+				writeProgress(pct)
+			}
+			return nil
+		})
+	}
 
-		resp, stat, err := makeRequest(&req)
-		if err != nil {
-			return err
+	go func() {
+		var (
+			resp *http.Response
+			stat request.RequestStat
+			err  error
+		)
+		if v != nil {
+			resp, stat, err = makeRequestWithVariant(req, v)
+		} else {
+			resp, stat, err = makeRequest(req)
 		}
 
-		writeRequestData(&req)
-		writeResponseData(resp, stat)
-	}
+		g.Update(func(g *gocui.Gui) error {
+			done = true
+			stopStream(g, nil)
+			RequestView.Clear()
+			if err != nil {
+				fmt.Fprintf(RequestView, "%s\n", err)
+				return nil
+			}
 
+			if v != nil {
+				writeRequestDataVariant(req, v)
+			} else {
+				writeRequestData(req)
+			}
+			writeSignature(resp, stat)
+			writeAssertions(stat)
+			switch {
+			case isStream(req, resp):
+				writeStream(g, orig, origV, resp, stat)
+			case req.Kind == request.KindGraphQL:
+				writeGraphQLResponseData(resp, stat)
+			default:
+				writeResponseData(resp, stat)
+			}
+			return nil
+		})
+	}()
 	return nil
 }
 
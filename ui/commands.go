@@ -6,7 +6,6 @@ import (
 	"sort"
 	"strings"
 
-	"github.com/hazbo/httpu/stash"
 	"github.com/hazbo/httpu/env"
 	"github.com/jroimartin/gocui"
 )
@@ -84,7 +83,7 @@ type StashCommand struct {
 func (sc StashCommand) Execute(g *gocui.Gui, cmd string, args []string) error {
 	defer cmdBarRefresh(g)
 	RequestView.Clear()
-	for n, s := range stash.Store {
+	for n, s := range client.Stash().Snapshot() {
 		fmt.Fprintf(RequestView, "%s: %s\n", n, s.Value)
 	}
 	return nil
@@ -167,4 +166,9 @@ var Commands map[string]Command = map[string]Command{
 	"!":             ShellCommand{},
 	"list-env":      ListEnvCommand{},
 	"set-env":       SetEnvCommand{},
+	"schema":        SchemaCommand{},
+	"send":          SendCommand{},
+	"disconnect":    DisconnectCommand{},
+	"proxy":         ProxyCommand{},
+	"reload":        ReloadCommand{},
 }
@@ -0,0 +1,244 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/hazbo/httpu/resource/request"
+	"github.com/hazbo/httpu/resource/request/graphql"
+	"github.com/hazbo/httpu/ui/printer"
+	"github.com/jroimartin/gocui"
+)
+
+// schema is the GraphQL schema most recently fetched using the schema command,
+// which is used to complete type and field names.
+var schema *graphql.Schema
+
+// writeGraphQLResponseData writes the response to a GraphQL request into the
+// response view, with the data and any errors being written separately. If
+// the response is not a GraphQL response, it is written as any other.
+func writeGraphQLResponseData(r *http.Response, stat request.RequestStat) {
+	defer r.Body.Close()
+	b, _ := ioutil.ReadAll(r.Body)
+	ResponseView.Clear()
+	ResponseView.Autoscroll = false
+
+	jp := printer.NewJSONPrinter()
+
+	gr, err := graphql.ParseResponse(b)
+	if err != nil || (gr.Data == nil && gr.Errors == nil) {
+		jp.PrintString(ResponseView, string(b))
+		writeResponseStatus(r, stat)
+		return
+	}
+
+	if len(gr.Errors) > 0 {
+		fmt.Fprint(ResponseView, printer.Color("Errors:\n", printer.ColorRed))
+		for _, e := range gr.Errors {
+			fmt.Fprintf(ResponseView, "%s\n", e)
+		}
+		fmt.Fprint(ResponseView, "\n")
+	}
+
+	fmt.Fprint(ResponseView, printer.Color("Data:\n", printer.ColorGreen))
+	jp.PrintString(ResponseView, string(gr.Data))
+
+	writeResponseStatus(r, stat)
+}
+
+// SchemaCommand represents the command that fetches and shows the schema of a
+// GraphQL endpoint.
+//
+// Usage:
+//
+//	schema             fetches the schema using the first GraphQL request
+//	schema <request>   fetches the schema using the given GraphQL request
+//	schema <type>      shows the fields of a type
+//	schema <type>.<field>
+type SchemaCommand struct {
+}
+
+// Execute fetches the schema or shows part of it, depending on the argument.
+func (sc SchemaCommand) Execute(g *gocui.Gui, cmd string, args []string) error {
+	defer cmdBarRefresh(g)
+	RequestView.Clear()
+
+	if len(args) > 1 {
+		return fmt.Errorf("schema expects at most 1 argument, %d passed.", len(args))
+	}
+
+	if len(args) == 0 {
+		r, ok := firstGraphQLRequest()
+		if !ok {
+			return fmt.Errorf("There are no GraphQL requests to fetch a schema with.")
+		}
+		fetchSchema(g, r)
+		return nil
+	}
+
+	if r, ok := requests()[args[0]]; ok && r.Kind == request.KindGraphQL {
+		fetchSchema(g, r)
+		return nil
+	}
+
+	if schema == nil {
+		return fmt.Errorf("No schema has been fetched, run schema first.")
+	}
+	return writeSchemaMember(args[0])
+}
+
+// firstGraphQLRequest returns the GraphQL request that comes first by name.
+func firstGraphQLRequest() (request.Request, bool) {
+	rm := requests()
+	var names []string
+	for n, r := range rm {
+		if r.Kind == request.KindGraphQL {
+			names = append(names, n)
+		}
+	}
+	if len(names) == 0 {
+		return request.Request{}, false
+	}
+	sort.Strings(names)
+	return rm[names[0]], true
+}
+
+// fetchSchema sends the introspection query to the endpoint of the given
+// request in the background, using the same headers and auth. Once fetched,
+// the schema is kept for completion and its types are listed.
+func fetchSchema(g *gocui.Gui, r request.Request) {
+	fmt.Fprintf(RequestView, "Fetching schema using %s...\n", r.Name)
+
+	req := r.WithQuery(graphql.IntrospectionQuery)
+	go func() {
+		resp, _, err := req.Make(client.Project().URL)
+
+		var s graphql.Schema
+		if err == nil {
+			b, _ := ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+			s, err = graphql.ParseSchema(b)
+		}
+
+		g.Update(func(g *gocui.Gui) error {
+			RequestView.Clear()
+			if err != nil {
+				fmt.Fprintf(RequestView, "%s\n", err)
+				return nil
+			}
+			schema = &s
+			writeSchemaTypes()
+			return nil
+		})
+	}()
+}
+
+// writeSchemaTypes lists the types of the schema in the request view.
+func writeSchemaTypes() {
+	b := bytes.NewBufferString(printer.Color("Types:\n", printer.ColorGreen))
+	for _, t := range schema.Types {
+		if strings.HasPrefix(t.Name, "__") {
+			continue
+		}
+		b.WriteString(fmt.Sprintf("%s (%s)\n", t.Name, strings.ToLower(t.Kind)))
+	}
+	fmt.Fprint(RequestView, b.String())
+}
+
+// writeSchemaMember shows the fields of a type, or a single field if given as
+// <type>.<field>, in the request view.
+func writeSchemaMember(name string) error {
+	tn, fn := name, ""
+	if i := strings.IndexByte(name, '.'); i >= 0 {
+		tn, fn = name[:i], name[i+1:]
+	}
+
+	t, ok := schema.Type(tn)
+	if !ok {
+		return fmt.Errorf("Type \"%s\" does not exist in the schema.", tn)
+	}
+
+	b := bytes.NewBufferString(printer.Color(
+		fmt.Sprintf("%s (%s):\n", t.Name, strings.ToLower(t.Kind)), printer.ColorGreen))
+
+	if fn != "" {
+		f, ok := t.Field(fn)
+		if !ok {
+			return fmt.Errorf("Field \"%s\" does not exist on %s.", fn, tn)
+		}
+		b.WriteString(fmt.Sprintf("%s\n", f))
+		if f.Description != "" {
+			b.WriteString(fmt.Sprintf("\n%s\n", f.Description))
+		}
+		fmt.Fprint(RequestView, b.String())
+		return nil
+	}
+
+	if t.Description != "" {
+		b.WriteString(fmt.Sprintf("%s\n\n", t.Description))
+	}
+	for _, f := range append(t.Fields, t.InputFields...) {
+		b.WriteString(fmt.Sprintf("%s\n", f))
+	}
+	for _, e := range t.EnumValues {
+		b.WriteString(fmt.Sprintf("%s\n", e.Name))
+	}
+	fmt.Fprint(RequestView, b.String())
+	return nil
+}
+
+// schemaCompletions returns the completions for the schema command currently
+// being typed into the command bar, along with the word being completed.
+func schemaCompletions() ([]string, string, bool) {
+	buf := cmdBarBuffer()
+	if schema == nil || !strings.HasPrefix(buf, "schema ") {
+		return nil, "", false
+	}
+	f := strings.Fields(buf)
+	var word string
+	if len(f) > 1 && !strings.HasSuffix(buf, " ") {
+		word = f[len(f)-1]
+	}
+	return schema.Complete(word), word, true
+}
+
+// commandKeyPress is called at the end of each keypress in command mode, and
+// lists the type and field names of the schema that match what is being typed
+// for the schema command.
+func commandKeyPress(v *gocui.View) error {
+	cs, _, ok := schemaCompletions()
+	if !ok {
+		return nil
+	}
+	RequestView.Clear()
+	for _, c := range cs {
+		fmt.Fprintf(RequestView, "%s\n", c)
+	}
+	return nil
+}
+
+// completeCmdBar completes the word being typed into the command bar with as
+// much of the matching type or field names as they have in common.
+func completeCmdBar(v *gocui.View) {
+	if HttpuMode != CommandMode {
+		return
+	}
+	cs, word, ok := schemaCompletions()
+	if !ok || len(cs) == 0 {
+		return
+	}
+
+	common := cs[0]
+	for _, c := range cs[1:] {
+		for !strings.HasPrefix(c, common) {
+			common = common[:len(common)-1]
+		}
+	}
+	for _, ch := range strings.TrimPrefix(common, word) {
+		v.EditWrite(ch)
+	}
+}
@@ -0,0 +1,103 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hazbo/httpu/prompt"
+	"github.com/jroimartin/gocui"
+)
+
+// promptState is the prompt currently being shown to the user, along with what
+// should happen once a value has been entered for it.
+type promptState struct {
+	prompt prompt.Prompt
+	done   func(g *gocui.Gui) error
+}
+
+// activePrompt is the prompt currently open, if any.
+var activePrompt *promptState
+
+// promptAll asks the user to enter a value for each of the given prompts, one
+// after the other, before calling done.
+func promptAll(g *gocui.Gui, ps prompt.Prompts, done func(*gocui.Gui) error) error {
+	if len(ps) == 0 {
+		return done(g)
+	}
+	return openPrompt(g, ps[0], func(g *gocui.Gui) error {
+		return promptAll(g, ps[1:], done)
+	})
+}
+
+// openPrompt creates the input dialog for a single prompt in the middle of the
+// screen. It is filled with the last value entered, or the default value, and
+// the input is masked if the prompt is for a secret.
+//
+// ┌──────────────────────────┐
+// │                          │
+// │       PromptView         │
+// │   ┌──────────────────┐   │
+// │   └──────────────────┘   │
+// │                          │
+// │                          │
+// └──────────────────────────┘
+func openPrompt(g *gocui.Gui, p prompt.Prompt, done func(*gocui.Gui) error) error {
+	maxX, maxY := g.Size()
+	d := NewUiSpec(maxX, maxY).PromptViewSpec.Dimensions()
+
+	v, err := g.SetView(promptView, d[0], d[1], d[2], d[3])
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+
+	v.Clear()
+	v.Editable = true
+	v.Title = p.Label()
+	v.Mask = 0
+	if p.Secret {
+		v.Mask = '*'
+	}
+
+	initial := p.Initial(client.Prompts())
+	fmt.Fprint(v, initial)
+	v.SetCursor(len(initial), 0)
+
+	activePrompt = &promptState{prompt: p, done: done}
+
+	_, err = g.SetCurrentView(promptView)
+	return err
+}
+
+// promptEnter stores the value entered for the active prompt, closes it and
+// moves on to whatever is waiting for the value.
+func promptEnter(g *gocui.Gui, v *gocui.View) error {
+	if activePrompt == nil {
+		return nil
+	}
+	ap := activePrompt
+
+	client.Prompts().Set(ap.prompt.Name, strings.Trim(v.Buffer(), "\n"))
+
+	if err := closePrompt(g); err != nil {
+		return err
+	}
+	return ap.done(g)
+}
+
+// promptCancel closes the active prompt without the request being made.
+func promptCancel(g *gocui.Gui, v *gocui.View) error {
+	return closePrompt(g)
+}
+
+// closePrompt removes the prompt view and moves the cursor back to the command
+// bar.
+func closePrompt(g *gocui.Gui) error {
+	activePrompt = nil
+	if err := g.DeleteView(promptView); err != nil {
+		return err
+	}
+	_, err := g.SetCurrentView(cmdBar)
+	return err
+}
@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/hazbo/httpu/resource/request"
+	"github.com/jroimartin/gocui"
+)
+
+// ProxyCommand represents the command that turns proxies on and off, or routes
+// every request through the given proxy, such as a local intercepting proxy.
+//
+// Usage: proxy [on|off|reset|{url}]
+type ProxyCommand struct {
+}
+
+// Execute toggles the proxy when no argument is given. Otherwise, on and off
+// turn it on and off, reset stops using a proxy set from the command bar, and
+// anything else is used as the url of the proxy.
+func (pc ProxyCommand) Execute(g *gocui.Gui, cmd string, args []string) error {
+	defer cmdBarRefresh(g)
+	if len(args) > 1 {
+		return fmt.Errorf("proxy expects at most 1 argument, %d passed.", len(args))
+	}
+	RequestView.Clear()
+
+	switch {
+	case len(args) == 0:
+		_, off := client.ProxyOverride().Get()
+		client.ProxyOverride().SetOff(!off)
+	case args[0] == "on":
+		client.ProxyOverride().SetOff(false)
+	case args[0] == "off":
+		client.ProxyOverride().SetOff(true)
+	case args[0] == "reset":
+		client.ProxyOverride().Set("")
+	default:
+		if _, err := request.ParseProxyURL(args[0]); err != nil {
+			return err
+		}
+		client.ProxyOverride().Set(args[0])
+	}
+
+	writeProxy()
+	return nil
+}
+
+// writeProxy writes the proxy that requests are being made through. Proxies
+// set by a request are not known until it is made, so only the proxy set from
+// the command bar or by the project is written.
+func writeProxy() {
+	p := client.Proxy()
+	url, off := client.ProxyOverride().Get()
+	switch {
+	case off:
+		fmt.Fprintln(RequestView, "Proxy off, requests are made directly.")
+	case p == nil:
+		fmt.Fprintln(RequestView,
+			"Proxy on, using any proxy set by the environment.")
+	case url != "":
+		fmt.Fprintf(RequestView, "Proxy on, using %s for every request.\n", p)
+	default:
+		fmt.Fprintf(RequestView, "Proxy on, using %s.\n", p)
+	}
+}
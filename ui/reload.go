@@ -0,0 +1,95 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hazbo/httpu/ui/printer"
+	"github.com/jroimartin/gocui"
+)
+
+// reloadInterval is how often the files of the project are checked for
+// changes.
+const reloadInterval = 500 * time.Millisecond
+
+var (
+	// HotReload reloads the project whenever any of its files change while
+	// the UI is running.
+	HotReload = true
+
+	// reloadFailed is set while the request view is showing why the project
+	// could not be reloaded.
+	reloadFailed = false
+)
+
+// ReloadCommand represents the command that reloads the project, which is
+// otherwise reloaded as its files change.
+//
+// Usage: reload
+type ReloadCommand struct {
+}
+
+// Execute reloads the project, writing whether it was reloaded.
+func (rc ReloadCommand) Execute(g *gocui.Gui, cmd string, args []string) error {
+	defer cmdBarRefresh(g)
+	if len(args) > 0 {
+		return fmt.Errorf("reload expects 0 arguments, %d passed.", len(args))
+	}
+	if reload(g) {
+		RequestView.Clear()
+		writeReloaded()
+	}
+	return nil
+}
+
+// watch starts watching the files of the project, reloading it within the
+// main loop whenever they change, until the UI is closed.
+func (u Ui) watch() chan struct{} {
+	stop := make(chan struct{})
+	go client.Watch(reloadInterval, func() {
+		u.Gui.Update(func(g *gocui.Gui) error {
+			reload(g)
+			return nil
+		})
+	}, stop)
+	return stop
+}
+
+// reload reloads the project, keeping the stash and whatever is being shown,
+// unless the README is being shown, in which case it is shown again in case it
+// has changed. If the project cannot be reloaded, the requests that were loaded
+// before are kept and the problems are written to the request view in place of
+// whatever it was showing, until the project has been reloaded.
+func reload(g *gocui.Gui) bool {
+	shown := strings.TrimSpace(RequestView.Buffer())
+	readme := shown != "" && shown == strings.TrimSpace(readmeShown)
+
+	if err := client.Reload(); err != nil {
+		reloadFailed = true
+		RequestView.Clear()
+		RequestView.SetOrigin(0, 0)
+		fmt.Fprint(RequestView, printer.Color(
+			"Could not reload project:\n\n", printer.ColorRed))
+		fmt.Fprintf(RequestView, "%s\n", err)
+		return false
+	}
+
+	switch {
+	case reloadFailed:
+		reloadFailed = false
+		RequestView.Clear()
+		writeReloaded()
+	case readme:
+		RequestView.Clear()
+		requestViewSetup(g)
+	}
+	return true
+}
+
+// writeReloaded writes that the project has been reloaded.
+func writeReloaded() {
+	fmt.Fprintf(RequestView, "%s%d requests loaded.\n",
+		printer.Color("Project reloaded.\n\n", printer.ColorGreen),
+		len(client.Project().Requests))
+}
@@ -25,6 +25,8 @@ type UiSpec struct {
 	CmdBarViewSpec      ViewSpec
 	StatusCodeViewSpec  ViewSpec
 	RequestTimeViewSpec ViewSpec
+	SizeViewSpec        ViewSpec
+	PromptViewSpec      ViewSpec
 }
 
 func NewUiSpec(maxX, maxY int) UiSpec {
@@ -69,5 +71,20 @@ func NewUiSpec(maxX, maxY int) UiSpec {
 			y1:    maxY - 1,
 			Wrap:  false,
 			Title: "Time",
+		},
+		SizeViewSpec: ViewSpec{
+			x0:    maxX/2 + 2 + 22,
+			y0:    maxY - 3,
+			x1:    maxX - 1,
+			y1:    maxY - 1,
+			Wrap:  false,
+			Title: "Size",
+		},
+		PromptViewSpec: ViewSpec{
+			x0:   maxX/2 - 30,
+			y0:   maxY/2 - 1,
+			x1:   maxX/2 + 30,
+			y1:   maxY/2 + 1,
+			Wrap: false,
 		}}
 }
@@ -27,3 +27,12 @@ func TestDimensions(t *testing.T) {
 		t.Error("Invalid dimensions for StatusCodeViewSpec")
 	}
 }
+
+func TestSizeViewDimensions(t *testing.T) {
+	uis := NewUiSpec(100, 100)
+
+	d := uis.SizeViewSpec.Dimensions()
+	if d[0] != 74 || d[1] != 97 || d[2] != 99 || d[3] != 99 {
+		t.Error("Invalid dimensions for SizeViewSpec")
+	}
+}
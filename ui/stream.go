@@ -0,0 +1,227 @@
+package ui
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hazbo/httpu/resource/request"
+	"github.com/hazbo/httpu/ui/printer"
+	"github.com/jroimartin/gocui"
+)
+
+// stream represents a streamed response that is being written into the
+// response view as it arrives.
+type stream struct {
+	mu      sync.Mutex
+	stopped bool
+	body    io.Closer
+}
+
+// activeStream is the stream currently being written, if there is one. It is
+// only accessed from within the main loop of the user interface.
+var activeStream *stream
+
+// setBody sets the body currently being read, so that it can be closed when
+// the stream is stopped. False is returned if the stream has already been
+// stopped, in which case the body is closed straight away.
+func (s *stream) setBody(b io.Closer) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		b.Close()
+		return false
+	}
+	s.body = b
+	return true
+}
+
+// stop stops the stream, closing the body that is being read.
+func (s *stream) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stopped = true
+	if s.body != nil {
+		s.body.Close()
+	}
+}
+
+// isStopped returns true once the stream has been stopped.
+func (s *stream) isStopped() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stopped
+}
+
+// stopStream stops the active stream, if there is one. It is bound to Ctrl+X.
+func stopStream(g *gocui.Gui, v *gocui.View) error {
+	if activeStream == nil {
+		return nil
+	}
+	activeStream.stop()
+	activeStream = nil
+	return nil
+}
+
+// writeStream writes a streamed response into the response view as it
+// arrives. Server-sent events are written one event at a time, with anything
+// else being written a line at a time. When an event stream ends without being
+// stopped, the request is made again with the Last-Event-ID header set, after
+// waiting for as long as the server asked.
+//
+// The request and variant given should be as they were before the first
+// request was made, as they are made again when reconnecting.
+func writeStream(
+	g *gocui.Gui,
+	req request.Request,
+	v *request.Variant,
+	resp *http.Response,
+	stat request.RequestStat) {
+
+	stopStream(g, nil)
+	s := &stream{}
+	activeStream = s
+
+	ResponseView.Clear()
+	ResponseView.Autoscroll = true
+	writeResponseStatus(resp, stat)
+	fmt.Fprint(RequestTimeView, " streaming")
+
+	write := func(f func()) {
+		g.Update(func(g *gocui.Gui) error {
+			if activeStream == s {
+				f()
+			}
+			return nil
+		})
+	}
+
+	go func() {
+		var lastEventID string
+		for {
+			if !s.setBody(resp.Body) {
+				return
+			}
+			st := stat
+
+			var (
+				err   error
+				retry = request.DefaultRetry
+				sse   = request.IsEventStream(resp)
+			)
+			if sse {
+				er := request.NewEventReader(resp.Body, lastEventID)
+				for {
+					var ev request.Event
+					if ev, err = er.Next(); err != nil {
+						break
+					}
+					write(func() { writeEvent(ev, st) })
+				}
+				lastEventID, retry = er.LastEventID, er.Retry
+			} else {
+				br := bufio.NewReader(resp.Body)
+				for {
+					var l string
+					l, err = br.ReadString('\n')
+					if l != "" {
+						line := strings.TrimRight(l, "\r\n")
+						write(func() { writeStreamLine(line, st) })
+					}
+					if err != nil {
+						break
+					}
+				}
+			}
+			resp.Body.Close()
+
+			if s.isStopped() {
+				write(func() { writeStreamEnd("stopped") })
+				return
+			}
+			if !sse {
+				msg := "ended"
+				if err != io.EOF {
+					msg = err.Error()
+				}
+				write(func() { writeStreamEnd(msg) })
+				return
+			}
+
+			write(func() {
+				writeStreamEnd(fmt.Sprintf("reconnecting in %s", retry))
+			})
+			time.Sleep(retry)
+			if s.isStopped() {
+				return
+			}
+
+			if resp, stat, err = reconnect(req, v, lastEventID); err != nil {
+				msg := err.Error()
+				write(func() { writeStreamEnd(msg) })
+				return
+			}
+			r, st := resp, stat
+			write(func() { writeResponseStatus(r, st) })
+		}
+	}()
+}
+
+// reconnect makes a copy of the given request again, with the ID of the last
+// event received, if there was one.
+func reconnect(
+	req request.Request,
+	v *request.Variant,
+	lastEventID string) (*http.Response, request.RequestStat, error) {
+
+	r := req.Clone()
+	if lastEventID != "" {
+		if r.Spec.Headers == nil {
+			r.Spec.Headers = http.Header{}
+		}
+		r.Spec.Headers.Set(request.LastEventIDHeader, lastEventID)
+	}
+	if v == nil {
+		return makeRequest(&r)
+	}
+	rv := v.Clone()
+	return makeRequestWithVariant(&r, &rv)
+}
+
+// writeEvent writes a single server-sent event, with its type and ID.
+func writeEvent(ev request.Event, stat request.RequestStat) {
+	ts := time.Now().Format("15:04:05.000")
+
+	var meta []string
+	if ev.Event != "" {
+		meta = append(meta, ev.Event)
+	}
+	if ev.ID != "" {
+		meta = append(meta, fmt.Sprintf("id: %s", ev.ID))
+	}
+	fmt.Fprintf(ResponseView, "%s %s\n", ts,
+		printer.Color(strings.Join(meta, " "), printer.ColorYellow))
+	fmt.Fprintf(ResponseView, "%s\n\n", ev.Data)
+	writeSize(stat)
+}
+
+// writeStreamLine writes a single line of a streamed response.
+func writeStreamLine(l string, stat request.RequestStat) {
+	fmt.Fprintf(ResponseView, "%s %s\n", time.Now().Format("15:04:05.000"), l)
+	writeSize(stat)
+}
+
+// writeStreamEnd writes why a stream has ended, or is reconnecting.
+func writeStreamEnd(msg string) {
+	fmt.Fprintf(ResponseView, "%s\n", printer.Color(fmt.Sprintf("(%s)", msg), printer.ColorRed))
+}
+
+// isStream returns true if the response to the given request should be
+// written as it arrives.
+func isStream(req *request.Request, resp *http.Response) bool {
+	return req.Spec.Stream || request.IsStream(resp)
+}
@@ -2,9 +2,7 @@ package ui
 
 import (
 	"fmt"
-	"io/ioutil"
 	"log"
-	"os"
 	"strings"
 
 	"github.com/hazbo/httpu"
@@ -23,6 +21,8 @@ const (
 	responseView     = "response_view"
 	statusCodeView   = "status_code_view"
 	requestTimeView  = "request_time_view"
+	sizeView         = "size_view"
+	promptView       = "prompt_view"
 	defaultPromptMsg = "(httpu) "
 	commandPromptMsg = "(httpu) :"
 	welcomeMessage   = "Welcome to httpu!"
@@ -48,8 +48,17 @@ var (
 	CmdBarView      *gocui.View
 	StatusCodeView  *gocui.View
 	RequestTimeView *gocui.View
+	SizeView        *gocui.View
 
 	readmeMsg = ""
+
+	// client is the client that the project being used was loaded into, which
+	// every request is looked up within and made through.
+	client *httpu.Client
+
+	// readmeShown is what was last written to the request view by
+	// requestViewSetup, so that it can be shown again once it has changed.
+	readmeShown = ""
 )
 
 // Toggle changes the mode from either default to command or the other way
@@ -63,13 +72,20 @@ func (m *Mode) Toggle() {
 	}
 }
 
-// New returns a new instance of UI with a pre-configured layout.
-func New() Ui {
+// New returns a new instance of UI with a pre-configured layout, for the project
+// loaded into the given client.
+func New(c *httpu.Client) Ui {
+	client = c
+
 	g, err := gocui.NewGui(gocui.OutputNormal)
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	// Esc is used to close dialogs such as prompts, so it should not be treated
+	// as the start of an Alt key sequence.
+	g.InputEsc = true
+
 	g.SetManagerFunc(layout)
 
 	return Ui{Gui: g}
@@ -84,6 +100,10 @@ func (u Ui) Start() {
 	cmdBarSetup(u.Gui)
 	requestViewSetup(u.Gui)
 
+	if HotReload {
+		defer close(u.watch())
+	}
+
 	if err := u.Gui.MainLoop(); err != nil && err != gocui.ErrQuit {
 		log.Panicln(err)
 	}
@@ -118,6 +138,11 @@ func layout(g *gocui.Gui) error {
 	if err != nil {
 		log.Fatal(err)
 	}
+
+	err = createSizeView(g, uis.SizeViewSpec)
+	if err != nil {
+		log.Fatal(err)
+	}
 	return nil
 }
 
@@ -156,6 +181,8 @@ func cmdBarEditor(v *gocui.View, key gocui.Key, ch rune, mod gocui.Modifier) {
 		v.EditWrite(ch)
 	case key == gocui.KeySpace:
 		v.EditWrite(' ')
+	case key == gocui.KeyTab:
+		completeCmdBar(v)
 	case key == gocui.KeyBackspace || key == gocui.KeyBackspace2:
 		cx, _ := v.Cursor()
 		if cx > len(defaultPromptMsg) && HttpuMode == DefaultMode {
@@ -215,6 +242,16 @@ func cmdBarBuffer() string {
 		strings.Replace(b, msg, "", 1), "\n")
 }
 
+// cmdBarArgs splits the command bar buffer into the name of the request and any
+// arguments that have been passed along with it.
+func cmdBarArgs() (string, []string) {
+	f := strings.Fields(cmdBarBuffer())
+	if len(f) == 0 {
+		return "", nil
+	}
+	return f[0], f[1:]
+}
+
 // createRequestView creates the left-hand view where request data can be seen
 // and modified.
 //
@@ -243,18 +280,15 @@ func createRequestView(g *gocui.Gui, vs ViewSpec) error {
 
 func requestViewSetup(g *gocui.Gui) {
 	g.Update(func(g *gocui.Gui) error {
-		rdmeFile := fmt.Sprintf("%s/README", httpu.Session().ProjectPath)
-		if _, err := os.Stat(rdmeFile); err == nil {
-			rdme, _ := ioutil.ReadFile(rdmeFile)
+		if rdme, err := client.Project().README(); err == nil {
 			readmeMsg = string(rdme)
 			x, _ := RequestView.Size()
 
-			wrapped := wordwrap.WrapString(readmeMsg, uint(x))
-
-			fmt.Fprintln(RequestView, wrapped)
+			readmeShown = wordwrap.WrapString(readmeMsg, uint(x))
 		} else {
-			fmt.Fprintln(RequestView, welcomeMessage)
+			readmeShown = welcomeMessage
 		}
+		fmt.Fprintln(RequestView, readmeShown)
 		return nil
 	})
 }
@@ -315,3 +349,18 @@ func createRequestTimeView(g *gocui.Gui, vs ViewSpec) error {
 	RequestTimeView = v
 	return nil
 }
+
+// createSizeView creates the view in which the size of the request and response
+// bodies are displayed, both as they were sent and once they were decoded.
+func createSizeView(g *gocui.Gui, vs ViewSpec) error {
+	d := vs.Dimensions()
+	v, err := g.SetView(sizeView, d[0], d[1], d[2], d[3])
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+	}
+	v.Title = vs.Title
+	SizeView = v
+	return nil
+}
@@ -0,0 +1,228 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/hazbo/httpu/resource/request"
+	"github.com/hazbo/httpu/ui/printer"
+	"github.com/jroimartin/gocui"
+)
+
+// webSocket is the WebSocket connection that is currently open, if there is
+// one, with webSocketName being the name of the resource it was opened for.
+// Only one connection is kept open at a time.
+//
+// webSocketConn is increased each time a connection is opened or closed, with
+// webSocketConnecting being the name of the resource that a connection is
+// being opened for. A connection that is no longer the latest once it has
+// been opened is closed. These are only used from within the main loop.
+var (
+	webSocket     *request.WebSocket
+	webSocketName string
+
+	webSocketConn       int
+	webSocketConnecting string
+)
+
+// connectWebSocket opens a connection for the given websocket resource, unless
+// one is already open for it. If a variant has been given, its message is sent
+// once connected. Frames are written into the response view as they are sent
+// and received.
+func connectWebSocket(
+	g *gocui.Gui, req *request.Request, v *request.Variant) error {
+
+	var (
+		msg []byte
+		err error
+	)
+	if v != nil {
+		if msg, err = req.Message(v); err != nil {
+			RequestView.Clear()
+			fmt.Fprintf(RequestView, "%s\n", err)
+			return nil
+		}
+	}
+
+	if webSocket != nil && webSocketName == req.Name {
+		if msg != nil {
+			return sendWebSocket(g, msg)
+		}
+		return nil
+	}
+	if webSocketConnecting == req.Name {
+		return nil
+	}
+
+	if webSocket != nil {
+		webSocket.Close()
+		webSocket = nil
+	}
+
+	RequestView.Clear()
+	fmt.Fprintf(RequestView, "Connecting to %s...\n", req.Name)
+
+	webSocketConn++
+	conn := webSocketConn
+	webSocketConnecting = req.Name
+
+	go func() {
+		ws, err := req.Connect(client.Project().URL, func(f request.Frame) {
+			g.Update(func(g *gocui.Gui) error {
+				if webSocketConn != conn {
+					return nil
+				}
+				writeFrame(f)
+				if f.Err != nil {
+					webSocket, webSocketConnecting = nil, ""
+					webSocketConn++
+					RequestTimeView.Clear()
+					fmt.Fprint(RequestTimeView, "closed")
+				}
+				return nil
+			})
+		})
+
+		g.Update(func(g *gocui.Gui) error {
+			if webSocketConn != conn {
+				if err == nil {
+					ws.Close()
+				}
+				return nil
+			}
+			webSocketConnecting = ""
+
+			RequestView.Clear()
+			if err != nil {
+				fmt.Fprintf(RequestView, "%s\n", err)
+				return nil
+			}
+
+			webSocket, webSocketName = ws, req.Name
+			writeWebSocketData(req, ws)
+
+			ResponseView.Clear()
+			ResponseView.Autoscroll = true
+
+			StatusCodeView.Clear()
+			StatusCodeView.BgColor = gocui.ColorGreen
+			fmt.Fprint(StatusCodeView, "  101")
+			RequestTimeView.Clear()
+			fmt.Fprint(RequestTimeView, "open")
+			SizeView.Clear()
+
+			if msg != nil {
+				return sendWebSocket(g, msg)
+			}
+			return nil
+		})
+	}()
+	return nil
+}
+
+// sendWebSocket sends a message over the open connection in the background,
+// as writing can block.
+func sendWebSocket(g *gocui.Gui, msg []byte) error {
+	ws := webSocket
+	if ws == nil {
+		return fmt.Errorf("There is no WebSocket connection open.")
+	}
+	go func() {
+		if err := ws.Send(msg); err != nil {
+			g.Update(func(g *gocui.Gui) error {
+				fmt.Fprintf(ResponseView, "%s\n", printer.Color(err.Error(), printer.ColorRed))
+				return nil
+			})
+		}
+	}()
+	return nil
+}
+
+// writeWebSocketData writes the resource that the connection was opened for
+// into the request view, along with the messages that can be sent.
+func writeWebSocketData(r *request.Request, ws *request.WebSocket) {
+	b := bytes.NewBufferString(printer.Color("WebSocket:\n", printer.ColorGreen))
+	b.WriteString(fmt.Sprintf("%s\n", r.Spec.Uri))
+	if ws.Protocol != "" {
+		b.WriteString(fmt.Sprintf("Protocol: %s\n", ws.Protocol))
+	}
+	b.WriteString("\n")
+
+	writeAuth(b, r.Auth(nil))
+
+	if len(r.Spec.Variants) > 0 {
+		b.WriteString(printer.Color("Messages:\n", printer.ColorGreen))
+		for _, v := range r.Spec.Variants {
+			b.WriteString(fmt.Sprintf("%s.%s\n", r.Name, v.Name))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("Use send <text> in command mode to send a message, and\n")
+	b.WriteString("disconnect to close the connection.\n")
+	fmt.Fprint(RequestView, b.String())
+}
+
+// writeFrame writes a single frame into the response view, with the time it
+// was sent or received.
+func writeFrame(f request.Frame) {
+	ts := f.Time.Format("15:04:05.000")
+
+	if f.Err != nil {
+		fmt.Fprintf(ResponseView, "%s %s\n", ts,
+			printer.Color(fmt.Sprintf("closed: %s", f.Err), printer.ColorRed))
+		return
+	}
+
+	dir := printer.Color("→", printer.ColorYellow)
+	if f.Incoming {
+		dir = printer.Color("←", printer.ColorGreen)
+	}
+
+	data := strings.TrimRight(string(f.Data), "\n")
+	if f.Binary {
+		data = fmt.Sprintf("(binary, %s)", formatBytes(int64(len(f.Data))))
+	}
+	fmt.Fprintf(ResponseView, "%s %s %s\n", ts, dir, data)
+}
+
+// SendCommand represents the command that sends typed text over the open
+// WebSocket connection.
+//
+// Usage: send {"type": "ping"}
+type SendCommand struct {
+}
+
+// Execute sends the arguments, joined by spaces, as a single text frame.
+func (sc SendCommand) Execute(g *gocui.Gui, cmd string, args []string) error {
+	defer cmdBarRefresh(g)
+	if len(args) == 0 {
+		return fmt.Errorf("send expects at least 1 argument, 0 passed.")
+	}
+	return sendWebSocket(g, []byte(strings.Join(args, " ")))
+}
+
+// DisconnectCommand represents the command that closes the open WebSocket
+// connection.
+//
+// Usage: disconnect
+type DisconnectCommand struct {
+}
+
+// Execute closes the connection, or stops one from being opened if it is still
+// being connected.
+func (dc DisconnectCommand) Execute(g *gocui.Gui, cmd string, args []string) error {
+	defer cmdBarRefresh(g)
+	if webSocketConnecting != "" {
+		webSocketConnecting = ""
+		webSocketConn++
+		RequestView.Clear()
+		return nil
+	}
+	if webSocket == nil {
+		return fmt.Errorf("There is no WebSocket connection open.")
+	}
+	webSocket.Close()
+	return nil
+}
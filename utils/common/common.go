@@ -7,10 +7,6 @@ import (
 	"github.com/mitchellh/go-homedir"
 )
 
-// ProjectPath represents the full path for the current project.
-// TODO: This shouldn't really be stored here - just for now.
-var ProjectPath string
-
 // HomeDir gets the user's home directory.
 func HomeDir() (string, error) {
 	return homedir.Dir()
@@ -0,0 +1,76 @@
+package common
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// dirFS is a file system for the files within a directory on disk.
+type dirFS string
+
+// DirFS returns a file system for the files within the given directory, in the
+// same way as os.DirFS. Unlike os.DirFS, names can lead outside of the
+// directory using "..", as projects can use files from the directories above
+// them, such as other projects that they include.
+func DirFS(dir string) fs.FS {
+	return dirFS(dir)
+}
+
+// Open is an implementation of fs.FS.
+func (d dirFS) Open(name string) (fs.File, error) {
+	f, err := os.Open(filepath.Join(string(d), filepath.FromSlash(name)))
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Dir returns the directory of a file system returned by DirFS, or false if the
+// file system is not one of a directory on disk.
+func Dir(fsys fs.FS) (string, bool) {
+	d, ok := fsys.(dirFS)
+	return string(d), ok
+}
+
+// namedFS is a file system with a name that the paths of its files are shown
+// within.
+type namedFS struct {
+	fs.FS
+	name string
+}
+
+// NamedFS returns the given file system with a name that the paths of its files
+// are shown within, such as the path of the archive that they were read from.
+func NamedFS(fsys fs.FS, name string) fs.FS {
+	return namedFS{fsys, name}
+}
+
+// Name returns the name of a file system returned by NamedFS, or false if the
+// file system has no name.
+func Name(fsys fs.FS) (string, bool) {
+	n, ok := fsys.(namedFS)
+	return n.name, ok
+}
+
+// DisplayPath returns the path of a file within the file system as it is shown,
+// such as within problems. Files within a directory on disk are shown with the
+// directory and those within a named file system are shown with its name,
+// while those within any other file system are shown as they are.
+func DisplayPath(fsys fs.FS, name string) string {
+	if dir, ok := Dir(fsys); ok {
+		return filepath.Join(dir, name)
+	}
+	if n, ok := Name(fsys); ok {
+		return path.Join(filepath.ToSlash(n), CleanPath(name))
+	}
+	return CleanPath(name)
+}
+
+// CleanPath returns the name of a file given within a project as it is opened
+// within a file system, with slashes as separators and without any leading
+// "./".
+func CleanPath(name string) string {
+	return path.Clean(filepath.ToSlash(name))
+}
@@ -90,3 +90,44 @@ func (vp VarParser) Parse(s string, vr VarReplacer) string {
 	}
 	return s
 }
+
+// Keys returns the key of each variable of the parser's kind found within the
+// given string, in the order in which they appear. Duplicate keys are only
+// returned once.
+func (vp VarParser) Keys(s string) []string {
+	var keys []string
+	vstart := fmt.Sprintf("%c%c%s%c",
+		tknDelimiter,
+		tknLeftBrace,
+		vp.kind,
+		tknLeftbracket)
+
+	for {
+		i := strings.Index(s, vstart)
+		if i < 0 {
+			return keys
+		}
+		s = s[i+len(vstart):]
+
+		end := strings.IndexByte(s, tknRightBracket)
+		if end < 0 {
+			return keys
+		}
+
+		k := s[:end]
+		if !contains(keys, k) {
+			keys = append(keys, k)
+		}
+		s = s[end:]
+	}
+}
+
+// contains checks to see if the given string exists within the slice.
+func contains(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
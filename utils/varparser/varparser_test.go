@@ -36,3 +36,12 @@ func TestParse(t *testing.T) {
 	res4 := vp4.Parse("${multi[hello]} string ${multi[world]}", r)
 	assert.Equal(t, "${multi[hello]} string ${multi[world]}", res4)
 }
+
+func TestKeys(t *testing.T) {
+	vp := VarParser{kind: "prompt"}
+	keys := vp.Keys("/orders/${prompt[id]}?a=${stash[b]}&c=${prompt[c]}&d=${prompt[id]}")
+	assert.Equal(t, []string{"id", "c"}, keys)
+
+	assert.Equal(t, 0, len(vp.Keys("no variables here")))
+	assert.Equal(t, 0, len(vp.Keys("${prompt[unterminated")))
+}
@@ -0,0 +1,118 @@
+package httpu
+
+import (
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/hazbo/httpu/resource"
+	utils "github.com/hazbo/httpu/utils/common"
+)
+
+// Watched returns the paths of the files and directories that the project of
+// the client was last loaded from: the project files of it and the projects it
+// includes, its resource files, the directories searched for them, the files
+// that requests load their data from and the README. Files that were looked
+// for but do not exist are included, so that they can be noticed once created.
+func (c *Client) Watched() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]string(nil), c.watched...)
+}
+
+// dataFiles returns the paths of the files that requests load their data and
+// GraphQL queries from as they are loaded.
+func dataFiles(fsys fs.FS, rm resource.RequestMap) []string {
+	var paths []string
+	add := func(file string) {
+		if file != "" {
+			paths = append(paths, utils.DisplayPath(fsys, file))
+		}
+	}
+	for _, r := range rm {
+		add(r.Spec.Data.FromFile)
+		if r.Spec.GraphQL != nil {
+			add(r.Spec.GraphQL.Query.FromFile)
+		}
+		for _, v := range r.Spec.Variants {
+			add(v.Data.FromFile)
+			if v.GraphQL != nil {
+				add(v.GraphQL.Query.FromFile)
+			}
+		}
+	}
+	return paths
+}
+
+// watchable returns the paths of the files of a project loaded from the given
+// file system that can be watched for changes. Only files on disk can be
+// watched, so a project within an archive is watched through the archive
+// itself, and one within any other file system is not watched at all.
+func watchable(fsys fs.FS, paths []string) []string {
+	if _, ok := utils.Dir(fsys); ok {
+		return paths
+	}
+	if name, ok := utils.Name(fsys); ok {
+		return []string{name}
+	}
+	return nil
+}
+
+// fileState is what is known about a watched file or directory when it was
+// last checked. Directories change as files are added to or removed from them.
+type fileState struct {
+	exists  bool
+	size    int64
+	modTime time.Time
+}
+
+// snapshot returns the state of each of the given paths.
+func snapshot(paths []string) map[string]fileState {
+	s := map[string]fileState{}
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			s[p] = fileState{}
+			continue
+		}
+		s[p] = fileState{true, info.Size(), info.ModTime()}
+	}
+	return s
+}
+
+// changedSince checks whether any of the paths within the snapshot have been
+// changed, created or removed since the previous one. Paths that have only
+// started to be watched are not seen as changes.
+func changedSince(previous, current map[string]fileState) bool {
+	for p, s := range current {
+		if ps, ok := previous[p]; ok && ps != s {
+			return true
+		}
+	}
+	return false
+}
+
+// Watch checks the files that the project of the client was loaded from each
+// interval, calling changed whenever any of them have changed, until stop is
+// closed. Reloading the project is left to changed, so that it can be done
+// where the requests are being used. The files are those given by Watched,
+// which are those from the latest time the project was loaded.
+func (c *Client) Watch(interval time.Duration, changed func(),
+	stop <-chan struct{}) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	last := snapshot(c.Watched())
+	for {
+		select {
+		case <-stop:
+			return
+		case <-t.C:
+		}
+		s := snapshot(c.Watched())
+		if changedSince(last, s) {
+			changed()
+		}
+		last = s
+	}
+}
@@ -0,0 +1,110 @@
+package httpu
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hazbo/httpu/stash"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChangedSince(t *testing.T) {
+	now := time.Now()
+	previous := map[string]fileState{
+		"a": {true, 1, now},
+		"b": {},
+	}
+
+	assert.False(t, changedSince(previous, map[string]fileState{
+		"a": {true, 1, now}, "b": {}, "c": {true, 2, now},
+	}), "newly watched paths are not changes")
+	assert.True(t, changedSince(previous, map[string]fileState{
+		"a": {true, 1, now.Add(time.Second)}, "b": {},
+	}), "modified files are changes")
+	assert.True(t, changedSince(previous, map[string]fileState{
+		"a": {}, "b": {},
+	}), "removed files are changes")
+	assert.True(t, changedSince(previous, map[string]fileState{
+		"a": {true, 1, now}, "b": {true, 0, now},
+	}), "created files are changes")
+}
+
+func TestWatchReload(t *testing.T) {
+	// Projects are loaded relative to the working directory, so the path of the
+	// temporary directory is made relative to it.
+	wd, err := os.Getwd()
+	assert.Nil(t, err)
+	dir, err := filepath.Rel(wd, t.TempDir())
+	assert.Nil(t, err)
+
+	write := func(name, contents string) {
+		p := filepath.Join(dir, name)
+		assert.Nil(t, os.MkdirAll(filepath.Dir(p), 0755))
+		assert.Nil(t, ioutil.WriteFile(p, []byte(contents), 0644))
+	}
+	write("project.json", `{"project": {"url": "http://localhost",
+		"resourceFiles": ["`+dir+`/requests"]}}`)
+	write("requests/a.json",
+		`{"kind": "request", "name": "a", "spec": {"uri": "/a", "method": "GET"}}`)
+
+	c := NewClient()
+	assert.Nil(t, c.Load(dir))
+	assert.Contains(t, c.Watched(), filepath.Join(dir, "requests"))
+	assert.Contains(t, c.Watched(), filepath.Join(dir, "requests/a.json"))
+	c.Stash().Set("token", stash.StashValue{Name: "token", Value: "abc"})
+	a := c.Project().Requests["a"]
+
+	changed := make(chan struct{}, 1)
+	stop := make(chan struct{})
+	defer close(stop)
+	go c.Watch(10*time.Millisecond, func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}, stop)
+	wait := func() {
+		select {
+		case <-changed:
+		case <-time.After(2 * time.Second):
+			t.Fatal("the change was not noticed")
+		}
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	write("requests/b.json",
+		`{"kind": "request", "name": "b", "spec": {"uri": "/b", "method": "GET"}}`)
+	wait()
+
+	// The project can be read while it is being reloaded, such as by requests
+	// being made in the background.
+	reading := make(chan struct{})
+	go func() {
+		defer close(reading)
+		for i := 0; i < 100; i++ {
+			_ = c.Project().URL
+			_ = c.Proxy()
+		}
+	}()
+	assert.Nil(t, c.Reload())
+	<-reading
+	rm := c.Project().Requests
+	assert.Contains(t, rm, "a")
+	assert.Contains(t, rm, "b")
+	sv, _ := c.Stash().Get("token")
+	assert.Equal(t, "abc", sv.Value, "the stash is kept")
+	assert.True(t, rm["a"].Scope == c.scope)
+	assert.True(t, a.Scope != c.scope,
+		"requests loaded before keep their own scope")
+	assert.True(t, a.Scope.Stash == c.scope.Stash)
+
+	time.Sleep(50 * time.Millisecond)
+	write("requests/a.json", `{"kind": "request", "name": "a", "spec": {`)
+	wait()
+	assert.NotNil(t, c.Reload())
+	assert.Contains(t, c.Project().Requests, "b",
+		"requests are kept when the project fails to reload")
+}